@@ -6,7 +6,10 @@ import (
 	"github.com/slack-go/slack"
 )
 
-const datePickTimeFmt = "2006-01-02"
+const (
+	datePickTimeFmt = "2006-01-02"
+	timePickTimeFmt = "15:04"
+)
 
 const (
 	CancelActionID = "cancel_action"
@@ -54,3 +57,59 @@ func NewDatePickerWithOpts(actionID string, placeholder *slack.TextBlockObject,
 func DateOptToTime(opt string) (time.Time, error) {
 	return time.Parse(datePickTimeFmt, opt)
 }
+
+// NewDatePickerInZone works like NewDatePickerWithOpts, but sets the initial
+// date to the day it is at initialDate in loc (e.g. the user's timezone, see
+// UserLocation) rather than in initialDate's own location
+func NewDatePickerInZone(actionID string, placeholder *slack.TextBlockObject, initialDate time.Time, loc *time.Location) *slack.DatePickerBlockElement {
+	return NewDatePickerWithOpts(actionID, placeholder, initialDate.In(loc))
+}
+
+// DateOptToTimeIn parses the selected date opt to midnight at the start of
+// that day in loc
+func DateOptToTimeIn(opt string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation(datePickTimeFmt, opt, loc)
+}
+
+// NewTimePickerWithOpts returns a new TimePickerBlockElement initialized with
+// its time/placeholder text set as specified by one of the two parameters.
+// The initial time is the time of day at initialTime in its own location, so
+// convert it with In first to show it in the user's timezone.
+func NewTimePickerWithOpts(actionID string, placeholder *slack.TextBlockObject, initialTime time.Time) *slack.TimePickerBlockElement {
+	picker := slack.NewTimePickerBlockElement(actionID)
+	if placeholder != nil {
+		picker.Placeholder = placeholder
+		return picker
+	}
+	picker.InitialTime = initialTime.Format(timePickTimeFmt)
+	return picker
+}
+
+// TimeOptToTime parses the selected time opt (e.g. 09:30) to that time of day
+// on the day and in the location of day
+func TimeOptToTime(opt string, day time.Time) (time.Time, error) {
+	t, err := time.Parse(timePickTimeFmt, opt)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	year, month, date := day.Date()
+	return time.Date(year, month, date, t.Hour(), t.Minute(), 0, 0, day.Location()), nil
+}
+
+// NewDateTimePickerWithOpts returns a new DateTimePickerBlockElement
+// initialized to initialDateTime. Slack shows the date and time in the
+// viewing user's own timezone.
+func NewDateTimePickerWithOpts(actionID string, initialDateTime time.Time) *slack.DateTimePickerBlockElement {
+	picker := slack.NewDateTimePickerBlockElement(actionID)
+	if !initialDateTime.IsZero() {
+		picker.InitialDateTime = initialDateTime.Unix()
+	}
+	return picker
+}
+
+// DateTimeOptToTime converts the selected date time opt, a UNIX timestamp, to
+// time.Time in loc
+func DateTimeOptToTime(opt int64, loc *time.Location) time.Time {
+	return time.Unix(opt, 0).In(loc)
+}
@@ -200,3 +200,110 @@ func TestTextBlock(t *testing.T) {
 		})
 	}
 }
+
+func TestNewDatePickerInZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("received unexpected error: %s", err)
+	}
+
+	// Late evening in UTC is already the next day in Tokyo
+	initialDate := time.Date(2020, time.March, 31, 20, 0, 0, 0, time.UTC)
+
+	picker := NewDatePickerInZone(mockActionID, nil, initialDate, tokyo)
+	wantDatePicker := &slack.DatePickerBlockElement{
+		Type:        slack.METDatepicker,
+		ActionID:    mockActionID,
+		InitialDate: "2020-04-01",
+	}
+	if diff := pretty.Compare(picker, wantDatePicker); diff != "" {
+		t.Fatalf("+got -want %s\n", diff)
+	}
+
+	parsedTime, err := DateOptToTimeIn(picker.InitialDate, tokyo)
+	if err != nil {
+		t.Fatalf("received unexpected error: %s", err)
+	}
+	if want := time.Date(2020, time.April, 1, 0, 0, 0, 0, tokyo); !parsedTime.Equal(want) || parsedTime.Location() != tokyo {
+		t.Fatalf("expected time: %v, got: %v", want, parsedTime)
+	}
+}
+
+func TestNewTimePickerWithOpts(t *testing.T) {
+	initialTime := time.Date(2020, time.April, 1, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		description    string
+		placeholder    *slack.TextBlockObject
+		wantTimePicker *slack.TimePickerBlockElement
+	}{
+		{
+			description: "returns new time picker with placeholder, which trumps initial time",
+			placeholder: mockTextObj,
+			wantTimePicker: &slack.TimePickerBlockElement{
+				Type:        slack.METTimepicker,
+				ActionID:    mockActionID,
+				Placeholder: mockTextObj,
+			},
+		},
+		{
+			description: "returns new time picker with proper initial time",
+			wantTimePicker: &slack.TimePickerBlockElement{
+				Type:        slack.METTimepicker,
+				ActionID:    mockActionID,
+				InitialTime: "09:30",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			picker := NewTimePickerWithOpts(mockActionID, tc.placeholder, initialTime)
+			if diff := pretty.Compare(picker, tc.wantTimePicker); diff != "" {
+				t.Fatalf("+got -want %s\n", diff)
+			}
+		})
+	}
+}
+
+func TestTimeOptToTime(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("received unexpected error: %s", err)
+	}
+	day := time.Date(2020, time.April, 1, 0, 0, 0, 0, tokyo)
+
+	parsedTime, err := TimeOptToTime("17:45", day)
+	if err != nil {
+		t.Fatalf("received unexpected error: %s", err)
+	}
+	if want := time.Date(2020, time.April, 1, 17, 45, 0, 0, tokyo); !parsedTime.Equal(want) {
+		t.Fatalf("expected time: %v, got: %v", want, parsedTime)
+	}
+
+	if _, err := TimeOptToTime("5:45pm", day); err == nil {
+		t.Fatal("expected error but did not receive one")
+	}
+}
+
+func TestNewDateTimePickerWithOpts(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("received unexpected error: %s", err)
+	}
+	initialDateTime := time.Date(2020, time.April, 1, 9, 30, 0, 0, tokyo)
+
+	picker := NewDateTimePickerWithOpts(mockActionID, initialDateTime)
+	wantDateTimePicker := &slack.DateTimePickerBlockElement{
+		Type:            slack.METDatetimepicker,
+		ActionID:        mockActionID,
+		InitialDateTime: initialDateTime.Unix(),
+	}
+	if diff := pretty.Compare(picker, wantDateTimePicker); diff != "" {
+		t.Fatalf("+got -want %s\n", diff)
+	}
+
+	parsedTime := DateTimeOptToTime(picker.InitialDateTime, tokyo)
+	if !parsedTime.Equal(initialDateTime) || parsedTime.Location() != tokyo {
+		t.Fatalf("expected time: %v, got: %v", initialDateTime, parsedTime)
+	}
+}
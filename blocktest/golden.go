@@ -0,0 +1,121 @@
+// Package blocktest provides snapshot testing for Block Kit output. Messages,
+// views and blocks are marshalled to canonical indented JSON and compared to
+// golden files under testdata, which are rewritten when tests are run with
+// the -update flag:
+//
+//	go test ./mypkg -update
+//
+// The -update flag is registered by this package, so it is only defined in
+// test binaries importing it: pass it to those packages rather than ./...,
+// where it fails any package that doesn't. Test packages importing blocktest
+// must not define a flag of the same name.
+package blocktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	utils "github.com/alyosha/slack-utils"
+	"github.com/kylelemons/godebug/diff"
+	"github.com/slack-go/slack"
+)
+
+// GoldenDir is the directory golden files are read from and written to,
+// relative to the package under test
+var GoldenDir = "testdata"
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// msgPayload is the canonical form of a utils.Msg, named as in the
+// chat.postMessage payload
+type msgPayload struct {
+	Text        string             `json:"text,omitempty"`
+	Blocks      []slack.Block      `json:"blocks,omitempty"`
+	Attachments []slack.Attachment `json:"attachments,omitempty"`
+	AsUser      bool               `json:"as_user,omitempty"`
+	IconURL     string             `json:"icon_url,omitempty"`
+}
+
+// AssertGolden compares v, typically a utils.Msg, a slack.ModalViewRequest or
+// a slice of blocks, to the golden file testdata/<name>.golden and fails the
+// test with a line diff if they differ. With -update the golden file is
+// written instead.
+func AssertGolden(t testing.TB, name string, v interface{}) {
+	t.Helper()
+
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal %s: %v", name, err)
+	}
+
+	path := filepath.Join(GoldenDir, name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create golden file directory: %v", err)
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run with -update to create it: %v", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match %s (-want +got):\n%s", name, path, diff.Diff(string(want), string(got)))
+	}
+}
+
+// Marshal returns the canonical JSON for v: indented by two spaces, with
+// object keys sorted, HTML characters left unescaped so mrkdwn stays legible,
+// and a trailing newline
+func Marshal(v interface{}) ([]byte, error) {
+	switch msg := v.(type) {
+	case utils.Msg:
+		v = newMsgPayload(msg)
+	case *utils.Msg:
+		v = newMsgPayload(*msg)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decoding to generic values sorts object keys when encoded again, so
+	// that struct field order doesn't matter
+	var generic interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(generic); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func newMsgPayload(msg utils.Msg) msgPayload {
+	return msgPayload{
+		Text:        msg.Body,
+		Blocks:      msg.Blocks,
+		Attachments: msg.Attachments,
+		AsUser:      msg.AsUser,
+		IconURL:     msg.IconURL,
+	}
+}
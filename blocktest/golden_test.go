@@ -0,0 +1,147 @@
+package blocktest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	utils "github.com/alyosha/slack-utils"
+	"github.com/slack-go/slack"
+)
+
+// recorder captures failures reported by AssertGolden, so that they can be
+// checked without failing the enclosing test. As with testing.T, Fatalf stops
+// the calling goroutine, so assertions are run with record.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+	runtime.Goexit()
+}
+
+func record(t *testing.T, assert func(testing.TB)) []string {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert(r)
+	}()
+	<-done
+
+	return r.failures
+}
+
+func mockSurveyMsg(question string) utils.Msg {
+	return utils.Msg{
+		Body: "New survey",
+		Blocks: []slack.Block{
+			slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Lunch poll", false, false)),
+			utils.NewTextBlock("<@U0G9QF9C6> asks: *"+question+"*", nil),
+			slack.NewActionBlock("survey_actions", utils.NewButton("answer", "survey-42", "Answer", slack.StylePrimary)),
+		},
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	AssertGolden(t, "survey_msg", mockSurveyMsg("Pizza &lt;or&gt; sushi?"))
+
+	view := slack.ModalViewRequest{
+		Type:   slack.VTModal,
+		Title:  slack.NewTextBlockObject(slack.PlainTextType, "Lunch poll", false, false),
+		Blocks: slack.Blocks{BlockSet: mockSurveyMsg("Pizza &lt;or&gt; sushi?").Blocks},
+	}
+	AssertGolden(t, "survey_view", view)
+}
+
+func TestAssertGoldenMismatch(t *testing.T) {
+	defer func(dir string, updating bool) {
+		GoldenDir, *update = dir, updating
+	}(GoldenDir, *update)
+
+	// A copy of the golden file is used, so that running with -update
+	// doesn't overwrite it with the mismatching output
+	GoldenDir, *update = t.TempDir(), false
+
+	golden, err := Marshal(mockSurveyMsg("Pizza &lt;or&gt; sushi?"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(GoldenDir, "survey_msg.golden"), golden, 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	failures := record(t, func(tb testing.TB) {
+		AssertGolden(tb, "survey_msg", mockSurveyMsg("Tacos?"))
+	})
+
+	if len(failures) != 1 {
+		t.Fatalf("expected a single failure, got: %v", failures)
+	}
+	for _, want := range []string{`-        "text": "<@U0G9QF9C6> asks: *Pizza &lt;or&gt; sushi?*",`, `+        "text": "<@U0G9QF9C6> asks: *Tacos?*",`} {
+		if !strings.Contains(failures[0], want) {
+			t.Fatalf("expected diff to contain %q, got:\n%s", want, failures[0])
+		}
+	}
+
+	failures = record(t, func(tb testing.TB) {
+		AssertGolden(tb, "missing", mockSurveyMsg("Tacos?"))
+	})
+	if len(failures) != 1 || !strings.HasPrefix(failures[0], "failed to read golden file") {
+		t.Fatalf("expected missing golden file failure, got: %v", failures)
+	}
+}
+
+func TestAssertGoldenUpdate(t *testing.T) {
+	defer func(dir string, updating bool) {
+		GoldenDir, *update = dir, updating
+	}(GoldenDir, *update)
+
+	GoldenDir, *update = t.TempDir(), true
+
+	AssertGolden(t, "nested/survey_msg", mockSurveyMsg("Tacos?"))
+
+	got, err := ioutil.ReadFile(filepath.Join(GoldenDir, "nested", "survey_msg.golden"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, _ := Marshal(mockSurveyMsg("Tacos?"))
+	if string(got) != string(want) {
+		t.Fatalf("expected golden file:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	got, err := Marshal(struct {
+		Zebra string `json:"zebra"`
+		Apple []int  `json:"apple"`
+		Link  string `json:"link"`
+	}{"z", []int{1, 2}, "<https://example.com?a=1&b=2|docs>"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{
+  "apple": [
+    1,
+    2
+  ],
+  "link": "<https://example.com?a=1&b=2|docs>",
+  "zebra": "z"
+}
+`
+	if string(got) != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -1,57 +1,132 @@
 package utils
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/slack-go/slack"
 	"golang.org/x/sync/errgroup"
 )
 
 // ChannelNameMaxLen is the max character length for a Slack channel name
-const ChannelNameMaxLen = 21
+const ChannelNameMaxLen = 80
+
+const (
+	// inviteBatchSize is the max number of users conversations.invite accepts per call
+	inviteBatchSize = 1000
+	// membersPageSize is the page size used when paging through conversations.members
+	membersPageSize = 1000
+	// maxRateLimitRetries is how many times a single call is retried after
+	// being rate limited before the error is recorded as a failure
+	maxRateLimitRetries = 3
+)
 
 const (
-	errInviteSelfMsg      = "cant_invite_self"
-	errAlreadyArchivedMsg = "already_archived"
+	errInviteSelfMsg       = "cant_invite_self"
+	errAlreadyInChannelMsg = "already_in_channel"
+	errAlreadyArchivedMsg  = "already_archived"
+	errNotInChannelMsg     = "not_in_channel"
+	errNotArchivedMsg      = "not_archived"
 )
 
-// Channel is used in opening/interacting with a single Slack channel
+// perUserInviteErrs are the conversations.invite errors caused by one of the
+// users invited, after which the rest of the batch can still be invited
+var perUserInviteErrs = map[string]bool{
+	errInviteSelfMsg:           true,
+	errAlreadyInChannelMsg:     true,
+	"cant_invite":              true,
+	"user_not_found":           true,
+	"user_is_restricted":       true,
+	"user_is_ultra_restricted": true,
+	"ura_max_channels":         true,
+}
+
+// BulkProgressFunc is called after each item of a bulk channel operation
+// completes, e.g. to update a status message. Calls are never concurrent.
+type BulkProgressFunc func(done, total int)
+
+// Channel is used in opening/interacting with a single Slack channel. Set
+// IsPrivate before calling CreateChannel to open a private channel instead of
+// a public one, and NameSuffix to have it retry with a suffixed name when the
+// requested one is taken (e.g. NumberedSuffix or DateSuffix). By default bulk
+// operations stop at the first failure, set ContinueOnError to attempt every
+// item and collect failures in the result. LeaveChannels, ArchiveChannels,
+// UnarchiveChannels and RemoveMembers run serially unless Concurrency is set
+// above 1, and report progress through OnProgress if provided.
 type Channel struct {
-	UserClient *slack.Client
-	BotClient  *slack.Client
-	ChannelID  string
+	UserClient      *slack.Client
+	BotClient       *slack.Client
+	ChannelID       string
+	IsPrivate       bool
+	NameSuffix      ChannelNameSuffix
+	ContinueOnError bool
+	Concurrency     int
+	OnProgress      BulkProgressFunc
+}
+
+// BulkResult reports the outcome of a bulk channel operation for each of the
+// user or channel IDs it was given. IDs that were not attempted because an
+// earlier item failed appear in none of the lists.
+type BulkResult struct {
+	Succeeded []string
+	Skipped   []BulkSkipped
+	Failed    []BulkFailed
+}
+
+// BulkSkipped is an ID which required no action, along with the reason
+// reported by Slack (e.g. already_in_channel, already_archived, cant_invite_self)
+type BulkSkipped struct {
+	ID     string
+	Reason string
+}
+
+// BulkFailed is an ID for which the operation failed
+type BulkFailed struct {
+	ID  string
+	Err error
+}
+
+// record files the ID under the list matching err, treating any of the
+// benign error messages as a skip. The error is returned only for failures.
+func (r *BulkResult) record(id string, err error, benignMsgs ...string) error {
+	if err == nil {
+		r.Succeeded = append(r.Succeeded, id)
+		return nil
+	}
+
+	for _, msg := range benignMsgs {
+		if err.Error() == msg {
+			r.Skipped = append(r.Skipped, BulkSkipped{ID: id, Reason: msg})
+			return nil
+		}
+	}
+
+	r.Failed = append(r.Failed, BulkFailed{ID: id, Err: err})
+	return err
 }
 
 var ErrNoUsersInWorkplace = errors.New("no users in workplace")
 
-// CreateChannel opens a new public channel and invites the provided list of member IDs, optionally posting an initial message
+// CreateChannel opens a new public (or private if IsPrivate is set) channel and invites the provided list of member IDs, optionally posting an initial message.
+// The channel name is passed through NormalizeChannelName before use
 func (c *Channel) CreateChannel(channelName string, userIDs []string, initMsg Msg) error {
 	if c.UserClient == nil {
 		return errors.New("method requires user client")
 	}
 
-	channel, err := c.UserClient.CreateChannel(channelName)
+	channel, err := c.createConversation(channelName)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create new channel")
 	}
 
-	if err = c.InviteUsers(userIDs); err != nil {
+	if _, err = c.inviteUsers(channel.ID, userIDs); err != nil {
 		return errors.Wrapf(err, "failed to invite user to channel")
 	}
 
-	client := c.UserClient
-	if c.BotClient != nil {
-		client = c.BotClient
-	}
-
 	if initMsg.Body != "" {
-		_, _, err := client.PostMessage(
-			channel.ID,
-			slack.MsgOptionText(initMsg.Body, false),
-			slack.MsgOptionAttachments(initMsg.Attachments...),
-			slack.MsgOptionBlocks(initMsg.Blocks...),
-			slack.MsgOptionEnableLinkUnfurl(),
-		)
-		if err != nil {
+		if _, err := c.postChannelMsg(channel.ID, initMsg); err != nil {
 			return errors.Wrapf(err, "failed to post message")
 		}
 	}
@@ -61,93 +136,322 @@ func (c *Channel) CreateChannel(channelName string, userIDs []string, initMsg Ms
 	return nil
 }
 
-// InviteUsers invites multiple users to the channel
-func (c *Channel) InviteUsers(userIDs []string) error {
+// postChannelMsg posts the message as the bot user if a BotClient is set,
+// otherwise as the user, returning the message timestamp
+func (c *Channel) postChannelMsg(channelID string, msg Msg) (string, error) {
+	client := c.UserClient
+	if c.BotClient != nil {
+		client = c.BotClient
+	}
+
+	_, ts, err := client.PostMessage(
+		channelID,
+		slack.MsgOptionText(msg.Body, false),
+		slack.MsgOptionAttachments(msg.Attachments...),
+		slack.MsgOptionBlocks(msg.Blocks...),
+		slack.MsgOptionEnableLinkUnfurl(),
+	)
+
+	return ts, err
+}
+
+// createConversation opens the channel under the normalized name, trying
+// suffixed names via c.NameSuffix for as long as Slack reports them as taken
+func (c *Channel) createConversation(channelName string) (*slack.Channel, error) {
+	var channel *slack.Channel
+	err := c.retryNameTaken(channelName, func(name string) (err error) {
+		channel, err = c.UserClient.CreateConversation(slack.CreateConversationParams{
+			ChannelName: name,
+			IsPrivate:   c.IsPrivate,
+		})
+		return err
+	})
+
+	return channel, err
+}
+
+// InviteUsers invites multiple users to the channel, batching the requests
+// according to the conversations.invite per-call limit. Users who are already
+// members (or the calling user themselves) are reported as skipped.
+func (c *Channel) InviteUsers(userIDs []string) (*BulkResult, error) {
 	if c.UserClient == nil {
-		return errors.New("method requires user client")
+		return nil, errors.New("method requires user client")
 	}
 
-	for _, user := range userIDs {
-		_, err := c.UserClient.InviteUserToChannel(c.ChannelID, user)
-		if err != nil && err.Error() != errInviteSelfMsg {
+	return c.inviteUsers(c.ChannelID, userIDs)
+}
+
+func (c *Channel) inviteUsers(channelID string, userIDs []string) (*BulkResult, error) {
+	result := &BulkResult{}
+	ctx := context.Background()
+
+	invite := func(ids ...string) error {
+		return callWithRetry(ctx, func() error {
+			_, err := c.UserClient.InviteUsersToConversation(channelID, ids...)
 			return err
+		})
+	}
+
+	for start := 0; start < len(userIDs); start += inviteBatchSize {
+		end := start + inviteBatchSize
+		if end > len(userIDs) {
+			end = len(userIDs)
+		}
+		batch := userIDs[start:end]
+
+		err := invite(batch...)
+		if err == nil {
+			result.Succeeded = append(result.Succeeded, batch...)
+			continue
+		}
+
+		// Errors about the channel or the request apply to every user in the
+		// batch, as does any error for a batch of one
+		if len(batch) == 1 || !perUserInviteErrs[err.Error()] {
+			var failErr error
+			for _, userID := range batch {
+				if recordErr := result.record(userID, err, errInviteSelfMsg, errAlreadyInChannelMsg); recordErr != nil {
+					failErr = recordErr
+				}
+			}
+			if failErr != nil && !c.ContinueOnError {
+				return result, failErr
+			}
+			continue
+		}
+
+		// A single bad ID fails the whole batch, so fall back to inviting
+		// one at a time in order to attribute the outcome to each user
+		for _, userID := range batch {
+			err = result.record(userID, invite(userID), errInviteSelfMsg, errAlreadyInChannelMsg)
+			if err != nil && !c.ContinueOnError {
+				return result, err
+			}
 		}
 	}
 
-	return nil
+	return result, nil
 }
 
-// LeaveChannels allows the user whose token was used to create the API client to leave multiple channels
-func (c *Channel) LeaveChannels(channelIDs []string) error {
+// LeaveChannels allows the user whose token was used to create the API client
+// to leave multiple channels. Channels the user is not a member of are
+// reported as skipped.
+func (c *Channel) LeaveChannels(channelIDs []string) (*BulkResult, error) {
 	if c.UserClient == nil {
-		return errors.New("method requires user client")
+		return nil, errors.New("method requires user client")
 	}
 
-	for _, channelID := range channelIDs {
-		_, err := c.UserClient.LeaveChannel(channelID)
-		if err != nil {
-			return err
+	leave := func(channelID string) error {
+		notInChannel, err := c.UserClient.LeaveConversation(channelID)
+		if err == nil && notInChannel {
+			return errors.New(errNotInChannelMsg)
 		}
+		return err
 	}
 
-	return nil
+	return c.runBulk(channelIDs, leave, errNotInChannelMsg)
 }
 
-// ArchiveChannels allows the user whose token was used to create the API client to archive multiple channels
-func (c *Channel) ArchiveChannels(channelIDs []string) error {
+// ArchiveChannels allows the user whose token was used to create the API
+// client to archive multiple channels. Channels which are already archived
+// are reported as skipped.
+func (c *Channel) ArchiveChannels(channelIDs []string) (*BulkResult, error) {
 	if c.UserClient == nil {
-		return errors.New("method requires user client")
+		return nil, errors.New("method requires user client")
 	}
 
-	for _, channelID := range channelIDs {
-		err := c.UserClient.ArchiveChannel(channelID)
-		if err != nil && err.Error() != errAlreadyArchivedMsg {
-			return err
+	return c.runBulk(channelIDs, c.UserClient.ArchiveConversation, errAlreadyArchivedMsg)
+}
+
+// UnarchiveChannels allows the user whose token was used to create the API
+// client to unarchive multiple channels. Channels which are not archived are
+// reported as skipped.
+func (c *Channel) UnarchiveChannels(channelIDs []string) (*BulkResult, error) {
+	if c.UserClient == nil {
+		return nil, errors.New("method requires user client")
+	}
+
+	return c.runBulk(channelIDs, c.UserClient.UnArchiveConversation, errNotArchivedMsg)
+}
+
+// RenameChannel renames the channel, returning the name it was given. The new
+// name is passed through NormalizeChannelName before use and, if NameSuffix is
+// set, retried with suffixes while Slack reports it as taken.
+func (c *Channel) RenameChannel(channelName string) (string, error) {
+	if c.UserClient == nil {
+		return "", errors.New("method requires user client")
+	}
+
+	var renamed string
+	err := c.retryNameTaken(channelName, func(name string) error {
+		channel, err := c.UserClient.RenameConversation(c.ChannelID, name)
+		if err == nil {
+			renamed = channel.Name
 		}
+		return err
+	})
+
+	switch {
+	case err == ErrInvalidChannelName:
+		return "", err
+	case err != nil:
+		return "", errors.Wrapf(err, "failed to rename channel")
 	}
 
-	return nil
+	return renamed, nil
 }
 
-// GetChannelMembers returns a list of members for a given channel
-func GetChannelMembers(client *slack.Client, channelID string) ([]string, error) {
-	channel, err := client.GetChannelInfo(channelID)
-	if err != nil {
-		return nil, err
+// runBulk calls fn for each of ids with up to c.Concurrency calls in flight,
+// recording each outcome in the returned result. Unless ContinueOnError is
+// set, no further calls are started once one has failed, though calls already
+// in flight are allowed to finish.
+func (c *Channel) runBulk(ids []string, fn func(id string) error, benignMsgs ...string) (*BulkResult, error) {
+	concurrency := c.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
 	}
 
-	return channel.Members, nil
+	var (
+		mu      sync.Mutex
+		done    int
+		stopped bool
+	)
+
+	result := &BulkResult{}
+	sem := make(chan struct{}, concurrency)
+	eg, ctx := errgroup.WithContext(context.Background())
+
+	for _, id := range ids {
+		sem <- struct{}{}
+
+		mu.Lock()
+		stop := stopped
+		mu.Unlock()
+		if stop {
+			<-sem
+			break
+		}
+
+		id := id
+		eg.Go(func() error {
+			defer func() { <-sem }()
+
+			err := callWithRetry(ctx, func() error { return fn(id) })
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			err = result.record(id, err, benignMsgs...)
+			if err != nil && !c.ContinueOnError {
+				stopped = true
+			}
+
+			done++
+			if c.OnProgress != nil {
+				c.OnProgress(done, len(ids))
+			}
+
+			if c.ContinueOnError {
+				return nil
+			}
+			return err
+		})
+	}
+
+	return result, eg.Wait()
+}
+
+// callWithRetry calls fn, waiting and trying again whenever Slack responds
+// that the call was rate limited. Waiting is abandoned once ctx is done.
+func callWithRetry(ctx context.Context, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		rateLimitedErr, ok := err.(*slack.RateLimitedError)
+		if !ok || attempt == maxRateLimitRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(rateLimitedErr.RetryAfter):
+		}
+	}
 }
 
-// GetChannelMemberEmails returns a list of emails for members of a given channel
-func GetChannelMemberEmails(client *slack.Client, channelID string) ([]string, error) {
+// GetChannelMembers returns a list of members for a given public or private
+// channel. If any filters are given, every user in the workspace is listed in
+// order to apply them, and members who cannot be found there are left out.
+func GetChannelMembers(client *slack.Client, channelID string, filters ...UserFilter) ([]string, error) {
+	if len(filters) == 0 {
+		return getChannelMembers(client, channelID)
+	}
+
 	var eg errgroup.Group
 	var memberIDs []string
-	var allUsers []slack.User
+	directory := NewUserDirectory(client, 0)
 
 	eg.Go(func() error {
-		channel, err := client.GetChannelInfo(channelID)
+		ids, err := getChannelMembers(client, channelID)
 		if err == nil {
-			memberIDs = channel.Members
+			memberIDs = ids
 		}
 		return err
 	})
 
+	eg.Go(directory.Refresh)
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return directory.FilterIDs(memberIDs, filters...)
+}
+
+func getChannelMembers(client *slack.Client, channelID string) ([]string, error) {
+	var memberIDs []string
+	params := &slack.GetUsersInConversationParameters{
+		ChannelID: channelID,
+		Limit:     membersPageSize,
+	}
+
+	for {
+		ids, cursor, err := client.GetUsersInConversation(params)
+		if err != nil {
+			return nil, err
+		}
+
+		memberIDs = append(memberIDs, ids...)
+
+		if cursor == "" {
+			break
+		}
+		params.Cursor = cursor
+	}
+
+	return memberIDs, nil
+}
+
+// GetChannelMemberEmails returns a list of emails for members of a given
+// channel, leaving out members not passing all of the filters
+func GetChannelMemberEmails(client *slack.Client, channelID string, filters ...UserFilter) ([]string, error) {
+	var eg errgroup.Group
+	var memberIDs []string
+	directory := NewUserDirectory(client, 0)
+
 	eg.Go(func() error {
-		users, err := getAll(client)
+		ids, err := getChannelMembers(client, channelID)
 		if err == nil {
-			allUsers = users
+			memberIDs = ids
 		}
 		return err
 	})
 
+	eg.Go(directory.Refresh)
+
 	if err := eg.Wait(); err != nil {
 		return nil, err
 	}
 
-	if len(allUsers) == 0 {
-		return nil, ErrNoUsersInWorkplace
-	}
-
-	return toEmails(allUsers, memberIDs), nil
+	return directory.SlackIDsToEmails(memberIDs, filters...)
 }
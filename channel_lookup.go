@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+// channelsPageSize is the page size used when paging through conversations.list
+const channelsPageSize = 1000
+
+var ErrChannelNotFound = errors.New("channel not found")
+
+var (
+	channelIDPattern      = regexp.MustCompile(`^[CGD][A-Z0-9]{6,}$`)
+	channelMentionPattern = regexp.MustCompile(`^<#([CGD][A-Z0-9]+)(?:\|[^>]*)?>$`)
+	// listedChannelTypes covers every channel the token can see, public or private
+	listedChannelTypes = []string{"public_channel", "private_channel"}
+)
+
+// ChannelResolver finds channels by name, including archived and private
+// channels visible to the client's token. Set CacheTTL to keep the channel
+// listing in memory between lookups instead of paging through
+// conversations.list every time; a zero value disables caching.
+type ChannelResolver struct {
+	Client   *slack.Client
+	CacheTTL time.Duration
+
+	mu       sync.Mutex
+	byName   map[string]slack.Channel
+	loadedAt time.Time
+}
+
+// FindChannelByName looks up a channel by name without caching, see
+// ChannelResolver.FindChannelByName
+func FindChannelByName(client *slack.Client, name string) (*slack.Channel, error) {
+	r := &ChannelResolver{Client: client}
+	return r.FindChannelByName(name)
+}
+
+// ResolveChannel resolves a channel reference without caching, see
+// ChannelResolver.ResolveChannel
+func ResolveChannel(client *slack.Client, ref string) (string, error) {
+	r := &ChannelResolver{Client: client}
+	return r.ResolveChannel(ref)
+}
+
+// FindChannelByName returns the channel with the given name, which may be
+// prefixed with #. Returns ErrChannelNotFound if no such channel is visible.
+func (r *ChannelResolver) FindChannelByName(name string) (*slack.Channel, error) {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+
+	if r.CacheTTL > 0 {
+		return r.findCached(name)
+	}
+
+	var found *slack.Channel
+	err := listChannels(r.Client, func(channel slack.Channel) bool {
+		if channel.Name == name {
+			found = &channel
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if found == nil {
+		return nil, ErrChannelNotFound
+	}
+
+	return found, nil
+}
+
+// ResolveChannel returns the channel ID referenced by ref, which may be a
+// channel ID (C123), a channel name (#name or name) or a channel mention as
+// found in message and slash command text (<#C123|name>)
+func (r *ChannelResolver) ResolveChannel(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+
+	if match := channelMentionPattern.FindStringSubmatch(ref); match != nil {
+		return match[1], nil
+	}
+
+	if channelIDPattern.MatchString(ref) {
+		return ref, nil
+	}
+
+	channel, err := r.FindChannelByName(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return channel.ID, nil
+}
+
+func (r *ChannelResolver) findCached(name string) (*slack.Channel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.byName == nil || time.Since(r.loadedAt) > r.CacheTTL {
+		byName := make(map[string]slack.Channel)
+		err := listChannels(r.Client, func(channel slack.Channel) bool {
+			byName[channel.Name] = channel
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		r.byName = byName
+		r.loadedAt = time.Now()
+	}
+
+	channel, ok := r.byName[name]
+	if !ok {
+		return nil, ErrChannelNotFound
+	}
+
+	return &channel, nil
+}
+
+// listChannels pages through every public and private channel visible to the
+// client, archived or not, calling fn for each until it returns false
+func listChannels(client *slack.Client, fn func(channel slack.Channel) bool) error {
+	params := &slack.GetConversationsParameters{
+		Limit: channelsPageSize,
+		Types: listedChannelTypes,
+	}
+
+	for {
+		var channels []slack.Channel
+		var cursor string
+		err := callWithRetry(context.Background(), func() (err error) {
+			channels, cursor, err = client.GetConversations(params)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, channel := range channels {
+			if !fn(channel) {
+				return nil
+			}
+		}
+
+		if cursor == "" {
+			return nil
+		}
+		params.Cursor = cursor
+	}
+}
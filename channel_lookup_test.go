@@ -0,0 +1,207 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func newConversationsListServer(respFirstPage, respLastPage []byte, calls *int) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/conversations.list", func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		if r.FormValue("cursor") != "" {
+			_, _ = w.Write(respLastPage)
+			return
+		}
+		_, _ = w.Write(respFirstPage)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestFindChannelByName(t *testing.T) {
+	testCases := []struct {
+		description   string
+		name          string
+		respFirstPage []byte
+		wantID        string
+		wantCalls     int
+		wantErr       string
+	}{
+		{
+			description:   "channel found on first page stops paging",
+			name:          "general",
+			respFirstPage: []byte(mockConversationsListFirstPageResp),
+			wantID:        "C012AB3CD",
+			wantCalls:     1,
+		},
+		{
+			description:   "archived private channel found",
+			name:          "#incident-41",
+			respFirstPage: []byte(mockConversationsListFirstPageResp),
+			wantID:        "G0AKFJBEU",
+			wantCalls:     1,
+		},
+		{
+			description:   "channel found on later page",
+			name:          "#Incident-42",
+			respFirstPage: []byte(mockConversationsListFirstPageResp),
+			wantID:        "C061EG9T2",
+			wantCalls:     2,
+		},
+		{
+			description:   "channel not found",
+			name:          "random",
+			respFirstPage: []byte(mockConversationsListFirstPageResp),
+			wantCalls:     2,
+			wantErr:       "channel not found",
+		},
+		{
+			description:   "failure to list channels",
+			name:          "general",
+			respFirstPage: []byte(mockConversationsListErrResp),
+			wantCalls:     1,
+			wantErr:       "invalid_types",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var calls int
+			testServ := newConversationsListServer(tc.respFirstPage, []byte(mockConversationsListLastPageResp), &calls)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+			channel, err := FindChannelByName(client, tc.name)
+
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+			}
+
+			if tc.wantID != "" && channel.ID != tc.wantID {
+				t.Fatalf("expected channel id: %s, got: %s", tc.wantID, channel.ID)
+			}
+
+			if calls != tc.wantCalls {
+				t.Fatalf("expected %v list requests, got %v", tc.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestResolveChannel(t *testing.T) {
+	testCases := []struct {
+		description string
+		ref         string
+		wantID      string
+		wantCalls   int
+		wantErr     string
+	}{
+		{
+			description: "channel ID is returned as is",
+			ref:         "C1H9RESGL",
+			wantID:      "C1H9RESGL",
+		},
+		{
+			description: "channel mention with name",
+			ref:         "<#C1H9RESGL|busting>",
+			wantID:      "C1H9RESGL",
+		},
+		{
+			description: "channel mention without name",
+			ref:         " <#G0AKFJBEU> ",
+			wantID:      "G0AKFJBEU",
+		},
+		{
+			description: "channel name with hash",
+			ref:         "#incident-42",
+			wantID:      "C061EG9T2",
+			wantCalls:   2,
+		},
+		{
+			description: "bare channel name",
+			ref:         "general",
+			wantID:      "C012AB3CD",
+			wantCalls:   1,
+		},
+		{
+			description: "unknown channel name",
+			ref:         "#random",
+			wantCalls:   2,
+			wantErr:     "channel not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var calls int
+			testServ := newConversationsListServer([]byte(mockConversationsListFirstPageResp), []byte(mockConversationsListLastPageResp), &calls)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+			id, err := ResolveChannel(client, tc.ref)
+
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+			}
+
+			if id != tc.wantID {
+				t.Fatalf("expected channel id: %s, got: %s", tc.wantID, id)
+			}
+
+			if calls != tc.wantCalls {
+				t.Fatalf("expected %v list requests, got %v", tc.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestChannelResolverCache(t *testing.T) {
+	var calls int
+	testServ := newConversationsListServer([]byte(mockConversationsListFirstPageResp), []byte(mockConversationsListLastPageResp), &calls)
+	defer testServ.Close()
+
+	resolver := &ChannelResolver{
+		Client:   slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL))),
+		CacheTTL: time.Hour,
+	}
+
+	for _, ref := range []string{"#general", "#incident-42", "#incident-41"} {
+		if _, err := resolver.ResolveChannel(ref); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if _, err := resolver.ResolveChannel("#random"); err != ErrChannelNotFound {
+		t.Fatalf("expected error: %v, got: %v", ErrChannelNotFound, err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("expected channel list to be paged through once (2 requests), got %v requests", calls)
+	}
+}
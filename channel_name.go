@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	errNameTakenMsg = "name_taken"
+	// maxNameAttempts caps how many suffixed names CreateChannel will try
+	// after the requested name turns out to be taken
+	maxNameAttempts = 10
+	channelDateFmt  = "2006-01-02"
+)
+
+var ErrInvalidChannelName = errors.New("channel name is empty after normalization")
+
+// ChannelNameSuffix returns the suffix appended to a channel name on the
+// given attempt (starting from 1) after the original name was taken
+type ChannelNameSuffix func(attempt int) string
+
+// NumberedSuffix produces names of the form name-2, name-3, etc.
+func NumberedSuffix(attempt int) string {
+	return fmt.Sprint(attempt + 1)
+}
+
+// DateSuffix produces names of the form name-2006-01-02 using the provided
+// date, falling back to name-2006-01-02-2, etc. if that is taken as well
+func DateSuffix(date time.Time) ChannelNameSuffix {
+	return func(attempt int) string {
+		dateStr := date.Format(channelDateFmt)
+		if attempt == 1 {
+			return dateStr
+		}
+		return fmt.Sprintf("%s-%d", dateStr, attempt)
+	}
+}
+
+// NormalizeChannelName converts name into one Slack will accept: letters are
+// lowercased, spaces and punctuation become hyphens (collapsing repeats and
+// trimming them from either end) and the result is cut to ChannelNameMaxLen
+func NormalizeChannelName(name string) string {
+	var b strings.Builder
+	lastHyphen := true
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			b.WriteRune(r)
+			lastHyphen = false
+		case !lastHyphen:
+			b.WriteRune('-')
+			lastHyphen = true
+		}
+	}
+
+	return truncateChannelName(b.String(), ChannelNameMaxLen)
+}
+
+// retryNameTaken passes the normalized channel name to fn, which creates or
+// renames a channel. If NameSuffix is set, fn is called again with suffixed
+// names for as long as Slack reports the name as taken.
+func (c *Channel) retryNameTaken(channelName string, fn func(name string) error) error {
+	name := NormalizeChannelName(channelName)
+	if name == "" {
+		return ErrInvalidChannelName
+	}
+
+	tryName := name
+	for attempt := 1; ; attempt++ {
+		err := fn(tryName)
+		if err == nil || err.Error() != errNameTakenMsg || c.NameSuffix == nil || attempt > maxNameAttempts {
+			return err
+		}
+		tryName = suffixChannelName(name, c.NameSuffix(attempt))
+	}
+}
+
+// suffixChannelName appends suffix to name, shortening name as required to
+// keep the result within ChannelNameMaxLen. A suffix too long to fit replaces
+// the name entirely and is itself cut short.
+func suffixChannelName(name, suffix string) string {
+	name = truncateChannelName(name, ChannelNameMaxLen-len([]rune(suffix))-1)
+	if name == "" {
+		return truncateChannelName(suffix, ChannelNameMaxLen)
+	}
+	return name + "-" + suffix
+}
+
+func truncateChannelName(name string, maxLen int) string {
+	if maxLen < 0 {
+		maxLen = 0
+	}
+	runes := []rune(name)
+	if len(runes) > maxLen {
+		runes = runes[:maxLen]
+	}
+	return strings.Trim(string(runes), "-")
+}
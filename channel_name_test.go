@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeChannelName(t *testing.T) {
+	testCases := []struct {
+		description string
+		name        string
+		wantName    string
+	}{
+		{
+			description: "valid name is left untouched",
+			name:        "incident-42_db",
+			wantName:    "incident-42_db",
+		},
+		{
+			description: "uppercase letters are lowercased",
+			name:        "Incident-42",
+			wantName:    "incident-42",
+		},
+		{
+			description: "spaces and punctuation become single hyphens",
+			name:        "Q3 planning: Budget & Hiring!",
+			wantName:    "q3-planning-budget-hiring",
+		},
+		{
+			description: "leading and trailing separators are trimmed",
+			name:        "  #release notes.  ",
+			wantName:    "release-notes",
+		},
+		{
+			description: "non-latin letters are kept",
+			name:        "プロジェクト X",
+			wantName:    "プロジェクト-x",
+		},
+		{
+			description: "long names are cut to the max length",
+			name:        strings.Repeat("a", ChannelNameMaxLen+10),
+			wantName:    strings.Repeat("a", ChannelNameMaxLen),
+		},
+		{
+			description: "name without any allowed characters becomes empty",
+			name:        "!!! ???",
+			wantName:    "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			name := NormalizeChannelName(tc.name)
+			if name != tc.wantName {
+				t.Fatalf("expected name: %s, got: %s", tc.wantName, name)
+			}
+		})
+	}
+}
+
+func TestSuffixChannelName(t *testing.T) {
+	date := time.Date(2020, time.May, 20, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		description string
+		name        string
+		suffix      ChannelNameSuffix
+		attempt     int
+		wantName    string
+	}{
+		{
+			description: "numbered suffix starts from 2",
+			name:        "incident",
+			suffix:      NumberedSuffix,
+			attempt:     1,
+			wantName:    "incident-2",
+		},
+		{
+			description: "numbered suffix on later attempt",
+			name:        "incident",
+			suffix:      NumberedSuffix,
+			attempt:     4,
+			wantName:    "incident-5",
+		},
+		{
+			description: "date suffix on first attempt",
+			name:        "incident",
+			suffix:      DateSuffix(date),
+			attempt:     1,
+			wantName:    "incident-2020-05-20",
+		},
+		{
+			description: "date suffix is numbered on later attempts",
+			name:        "incident",
+			suffix:      DateSuffix(date),
+			attempt:     2,
+			wantName:    "incident-2020-05-20-2",
+		},
+		{
+			description: "name is shortened to make room for the suffix",
+			name:        strings.Repeat("a", ChannelNameMaxLen),
+			suffix:      NumberedSuffix,
+			attempt:     1,
+			wantName:    strings.Repeat("a", ChannelNameMaxLen-2) + "-2",
+		},
+		{
+			description: "suffix too long to fit replaces the name",
+			name:        "incident",
+			suffix:      func(int) string { return strings.Repeat("b", ChannelNameMaxLen+5) },
+			attempt:     1,
+			wantName:    strings.Repeat("b", ChannelNameMaxLen),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			name := suffixChannelName(tc.name, tc.suffix(tc.attempt))
+			if name != tc.wantName {
+				t.Fatalf("expected name: %s, got: %s", tc.wantName, name)
+			}
+		})
+	}
+}
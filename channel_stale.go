@@ -0,0 +1,341 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// historyPageSize is the page size used when paging through conversations.history
+	historyPageSize = 200
+	// staleWarningBlockID marks warning messages so later scans can tell when
+	// a channel was warned
+	staleWarningBlockID = "stale_channel_warning"
+	staleArchiveDateFmt = "Jan 2, 2006"
+)
+
+// StaleAction is the step the stale channel policy takes for a channel
+type StaleAction string
+
+const (
+	// StaleActionWarn means the channel is about to become stale and a
+	// warning should be posted to it
+	StaleActionWarn StaleAction = "warn"
+	// StaleActionPending means the channel was warned and the warning period
+	// has not yet run out
+	StaleActionPending StaleAction = "pending"
+	// StaleActionArchive means the channel was warned long enough ago and
+	// should be archived
+	StaleActionArchive StaleAction = "archive"
+)
+
+// StalePolicy configures which channels are considered stale. Channels are
+// warned WarnBefore ahead of reaching ArchiveAfter without human messages,
+// and archived once the warning has been up for at least WarnBefore. Leave
+// WarnBefore at zero to archive without warning.
+type StalePolicy struct {
+	ArchiveAfter time.Duration
+	WarnBefore   time.Duration
+	// NamePrefixes limits the policy to channels whose name starts with one
+	// of the prefixes (e.g. incident-). All channels are scanned if empty.
+	NamePrefixes []string
+	// Exclude lists IDs of channels that are never considered stale. The
+	// workspace's general channel is always excluded.
+	Exclude []string
+	// WarningText overrides the default warning message body
+	WarningText func(channel StaleChannel) string
+}
+
+// StaleChannel is a channel found to be stale (or about to be) by a scan
+type StaleChannel struct {
+	ID   string
+	Name string
+	// LastActivity is the time of the latest human message, or of the
+	// channel's creation if that is later. It is zero if neither falls within
+	// the policy's ArchiveAfter.
+	LastActivity time.Time
+	// WarnedAt is when the channel was warned, or zero if it has not been
+	WarnedAt  time.Time
+	ArchiveAt time.Time
+	Action    StaleAction
+}
+
+// StaleReport lists the channels the stale channel policy applies to, along
+// with any channels whose history could not be read
+type StaleReport struct {
+	Scanned  int
+	Channels []StaleChannel
+	Failed   []BulkFailed
+}
+
+// StaleResult holds the outcome of applying a StaleReport
+type StaleResult struct {
+	Warned   *BulkResult
+	Archived *BulkResult
+}
+
+// ScanStaleChannels pages through every channel visible to the UserClient
+// and reports those that have gone without human messages long enough for
+// the policy to warn about or archive them. A channel's creation counts as
+// activity, so new channels are left alone until they have been quiet long
+// enough. Nothing is changed; pass the
+// report to ApplyStalePolicy to act on it (e.g. after reviewing it).
+func (c *Channel) ScanStaleChannels(policy StalePolicy) (*StaleReport, error) {
+	if c.UserClient == nil {
+		return nil, errors.New("method requires user client")
+	}
+
+	if policy.ArchiveAfter <= 0 {
+		return nil, errors.New("policy requires ArchiveAfter")
+	}
+
+	var candidates []slack.Channel
+	err := listChannels(c.UserClient, func(channel slack.Channel) bool {
+		if policy.applies(channel) {
+			candidates = append(candidates, channel)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list channels")
+	}
+
+	now := time.Now()
+	report := &StaleReport{Scanned: len(candidates)}
+	for _, channel := range candidates {
+		var created time.Time
+		if channel.Created > 0 {
+			created = channel.Created.Time()
+		}
+
+		// Channels too new to be warned are skipped without reading history
+		if now.Sub(created) < policy.ArchiveAfter-policy.WarnBefore {
+			continue
+		}
+
+		lastActivity, warnedAt, err := c.lastActivity(channel.ID, now.Add(-policy.ArchiveAfter))
+		if err != nil {
+			report.Failed = append(report.Failed, BulkFailed{ID: channel.ID, Err: err})
+			continue
+		}
+
+		if created.After(lastActivity) && now.Sub(created) < policy.ArchiveAfter {
+			lastActivity = created
+		}
+
+		stale := StaleChannel{
+			ID:           channel.ID,
+			Name:         channel.Name,
+			LastActivity: lastActivity,
+			WarnedAt:     warnedAt,
+		}
+		if policy.evaluate(&stale, now) {
+			report.Channels = append(report.Channels, stale)
+		}
+	}
+
+	return report, nil
+}
+
+// ApplyStalePolicy posts warnings to the channels in the report due to be
+// warned (as the bot user if a BotClient is set) and archives the channels
+// due to be archived. The Channel's ContinueOnError, Concurrency and
+// OnProgress settings apply to both steps.
+func (c *Channel) ApplyStalePolicy(report *StaleReport, policy StalePolicy) (*StaleResult, error) {
+	if c.UserClient == nil {
+		return nil, errors.New("method requires user client")
+	}
+
+	var toWarn, toArchive []string
+	byID := make(map[string]StaleChannel, len(report.Channels))
+	for _, channel := range report.Channels {
+		byID[channel.ID] = channel
+		switch channel.Action {
+		case StaleActionWarn:
+			toWarn = append(toWarn, channel.ID)
+		case StaleActionArchive:
+			toArchive = append(toArchive, channel.ID)
+		}
+	}
+
+	warn := func(channelID string) error {
+		_, err := c.postChannelMsg(channelID, policy.warningMsg(byID[channelID]))
+		return err
+	}
+
+	var (
+		result = &StaleResult{}
+		err    error
+	)
+
+	result.Warned, err = c.runBulk(toWarn, warn)
+	if err != nil {
+		return result, errors.Wrapf(err, "failed to post warning")
+	}
+
+	result.Archived, err = c.ArchiveChannels(toArchive)
+	if err != nil {
+		return result, errors.Wrapf(err, "failed to archive channel")
+	}
+
+	return result, nil
+}
+
+// lastActivity pages back through the channel's history as far as oldest,
+// returning the time of the latest human message and of the latest stale
+// warning posted after it
+func (c *Channel) lastActivity(channelID string, oldest time.Time) (lastActivity, warnedAt time.Time, err error) {
+	params := &slack.GetConversationHistoryParameters{
+		ChannelID: channelID,
+		Oldest:    strconv.FormatInt(oldest.Unix(), 10),
+		Limit:     historyPageSize,
+	}
+
+	for {
+		var resp *slack.GetConversationHistoryResponse
+		err = callWithRetry(context.Background(), func() (err error) {
+			resp, err = c.UserClient.GetConversationHistory(params)
+			return err
+		})
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+
+		// Messages are returned newest first, so the first human message
+		// found is the latest one. Warnings are checked first, as they are
+		// posted as the user when there is no BotClient.
+		for _, msg := range resp.Messages {
+			if isStaleWarningMsg(msg) {
+				if warnedAt.IsZero() {
+					warnedAt = tsToTime(msg.Timestamp)
+				}
+				continue
+			}
+			if isHumanMsg(msg) {
+				return tsToTime(msg.Timestamp), warnedAt, nil
+			}
+		}
+
+		if !resp.HasMore || resp.ResponseMetaData.NextCursor == "" {
+			return time.Time{}, warnedAt, nil
+		}
+		params.Cursor = resp.ResponseMetaData.NextCursor
+	}
+}
+
+func (p StalePolicy) applies(channel slack.Channel) bool {
+	if channel.IsArchived || channel.IsGeneral {
+		return false
+	}
+
+	for _, id := range p.Exclude {
+		if channel.ID == id {
+			return false
+		}
+	}
+
+	if len(p.NamePrefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range p.NamePrefixes {
+		if strings.HasPrefix(channel.Name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// evaluate sets the action and archive date for the channel, returning
+// false if the channel is still active enough to be left alone
+func (p StalePolicy) evaluate(channel *StaleChannel, now time.Time) bool {
+	if !channel.LastActivity.IsZero() && now.Sub(channel.LastActivity) < p.ArchiveAfter-p.WarnBefore {
+		return false
+	}
+
+	switch {
+	case p.WarnBefore <= 0:
+		channel.Action = StaleActionArchive
+		channel.ArchiveAt = now
+	case channel.WarnedAt.IsZero():
+		channel.Action = StaleActionWarn
+		channel.ArchiveAt = now.Add(p.WarnBefore)
+	case now.Sub(channel.WarnedAt) >= p.WarnBefore:
+		channel.Action = StaleActionArchive
+		channel.ArchiveAt = now
+	default:
+		channel.Action = StaleActionPending
+		channel.ArchiveAt = channel.WarnedAt.Add(p.WarnBefore)
+	}
+
+	return true
+}
+
+func (p StalePolicy) warningMsg(channel StaleChannel) Msg {
+	text := fmt.Sprintf(
+		"This channel has had no activity for a while and will be archived on *%s*. Post a message to keep it open.",
+		channel.ArchiveAt.Format(staleArchiveDateFmt),
+	)
+	if p.WarningText != nil {
+		text = p.WarningText(channel)
+	}
+
+	block := NewTextBlock(text, nil)
+	block.BlockID = staleWarningBlockID
+
+	return Msg{
+		Body:   text,
+		Blocks: []slack.Block{block},
+	}
+}
+
+// isHumanMsg reports whether the message was posted by a person, as opposed
+// to a bot or a channel event such as a join or topic change
+func isHumanMsg(msg slack.Message) bool {
+	if msg.User == "" || msg.BotID != "" {
+		return false
+	}
+
+	switch msg.SubType {
+	case "", "thread_broadcast", "file_share", "me_message":
+		return true
+	}
+
+	return false
+}
+
+func isStaleWarningMsg(msg slack.Message) bool {
+	for _, block := range msg.Blocks.BlockSet {
+		if section, ok := block.(*slack.SectionBlock); ok && section.BlockID == staleWarningBlockID {
+			return true
+		}
+	}
+	return false
+}
+
+// tsToTime converts a Slack message timestamp (e.g. 1503435956.000247) to
+// time.Time, returning zero time if it cannot be parsed
+func tsToTime(ts string) time.Time {
+	secStr, microStr := ts, "0"
+	if i := strings.Index(ts, "."); i >= 0 {
+		secStr, microStr = ts[:i], ts[i+1:]
+	}
+
+	secs, err := strconv.ParseInt(secStr, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	micros, err := strconv.ParseInt(microStr, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(secs, micros*int64(time.Microsecond))
+}
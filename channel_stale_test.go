@@ -0,0 +1,235 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/slack-go/slack"
+)
+
+// mockStaleConversationsListResp takes the creation times of a channel created
+// yesterday and one created 25 days ago
+const mockStaleConversationsListResp = `{
+    "ok": true,
+    "channels": [
+        {"id": "C0GENERAL", "name": "general", "created": 1503435956, "is_channel": true, "is_general": true},
+        {"id": "C0ACTIVE1", "name": "incident-1", "created": 1503435956, "is_channel": true},
+        {"id": "C0IDLE001", "name": "incident-2", "created": 1503435956, "is_channel": true},
+        {"id": "C0SILENT1", "name": "incident-3", "created": 1503435956, "is_channel": true},
+        {"id": "C0PENDING", "name": "incident-4", "created": 1503435956, "is_channel": true},
+        {"id": "C0DUE0001", "name": "incident-5", "created": 1503435956, "is_channel": true},
+        {"id": "C0REVIVED", "name": "incident-6", "created": 1503435956, "is_channel": true},
+        {"id": "C0BOTONLY", "name": "incident-7", "created": 1503435956, "is_channel": true},
+        {"id": "C0ARCHIVE", "name": "incident-8", "created": 1503435956, "is_channel": true, "is_archived": true},
+        {"id": "C0EXCLUDE", "name": "incident-9", "created": 1503435956, "is_channel": true},
+        {"id": "C0USERDUE", "name": "incident-10", "created": 1503435956, "is_channel": true},
+        {"id": "C0NEWCHAN", "name": "incident-11", "created": %d, "is_channel": true},
+        {"id": "C0YOUNGER", "name": "incident-12", "created": %d, "is_channel": true},
+        {"id": "C0RANDOM1", "name": "random", "created": 1503435956, "is_channel": true}
+    ],
+    "response_metadata": {
+        "next_cursor": ""
+    }
+}`
+
+func TestScanStaleChannels(t *testing.T) {
+	now := time.Now()
+	daysAgo := func(days int) string {
+		return fmt.Sprintf("%d.000100", now.Add(-time.Duration(days)*24*time.Hour).Unix())
+	}
+
+	human := func(days int) map[string]interface{} {
+		return map[string]interface{}{"type": "message", "user": "U0G9QF9C6", "text": "hi", "ts": daysAgo(days)}
+	}
+	bot := func(days int) map[string]interface{} {
+		return map[string]interface{}{"type": "message", "subtype": "bot_message", "bot_id": "B19LU7CSY", "text": "beep", "ts": daysAgo(days)}
+	}
+	// userWarning is a warning posted through the UserClient, as happens
+	// when no BotClient is set
+	userWarning := func(days int) map[string]interface{} {
+		return map[string]interface{}{
+			"type": "message",
+			"user": "U1QNSQB9U",
+			"text": "archiving soon",
+			"ts":   daysAgo(days),
+			"blocks": []interface{}{map[string]interface{}{
+				"type":     "section",
+				"block_id": staleWarningBlockID,
+				"text":     map[string]interface{}{"type": "mrkdwn", "text": "archiving soon"},
+			}},
+		}
+	}
+	warning := func(days int) map[string]interface{} {
+		msg := userWarning(days)
+		msg["bot_id"] = "B19LU7CSY"
+		return msg
+	}
+
+	histories := map[string][]map[string]interface{}{
+		"C0ACTIVE1": {human(2), human(20)},
+		"C0IDLE001": {bot(3), human(25)},
+		"C0SILENT1": {},
+		"C0PENDING": {warning(3), human(26)},
+		"C0DUE0001": {warning(8)},
+		"C0REVIVED": {human(1), warning(8)},
+		"C0BOTONLY": {bot(1)},
+		"C0USERDUE": {userWarning(8), human(40)},
+		"C0YOUNGER": {},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/conversations.list", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, mockStaleConversationsListResp, now.Add(-24*time.Hour).Unix(), now.Add(-25*24*time.Hour).Unix())
+	})
+	mux.HandleFunc("/conversations.history", func(w http.ResponseWriter, r *http.Request) {
+		msgs, ok := histories[r.FormValue("channel")]
+		if !ok {
+			_, _ = w.Write([]byte(mockConversationMembersErrResp))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "messages": msgs})
+	})
+
+	testServ := httptest.NewServer(mux)
+	defer testServ.Close()
+
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+	channel := Channel{
+		UserClient: client,
+	}
+
+	policy := StalePolicy{
+		ArchiveAfter: 30 * 24 * time.Hour,
+		WarnBefore:   7 * 24 * time.Hour,
+		NamePrefixes: []string{"incident-"},
+		Exclude:      []string{"C0EXCLUDE"},
+	}
+
+	report, err := channel.ScanStaleChannels(policy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Scanned != 10 {
+		t.Fatalf("expected 10 channels scanned, got %v", report.Scanned)
+	}
+
+	gotActions := make(map[string]StaleAction)
+	for _, stale := range report.Channels {
+		gotActions[stale.ID] = stale.Action
+
+		// A channel's creation counts as its activity until it has gone
+		// ArchiveAfter without messages
+		if stale.ID == "C0YOUNGER" && stale.LastActivity.Unix() != now.Add(-25*24*time.Hour).Unix() {
+			t.Fatalf("expected creation time as last activity, got %v", stale.LastActivity)
+		}
+	}
+
+	wantActions := map[string]StaleAction{
+		"C0IDLE001": StaleActionWarn,
+		"C0SILENT1": StaleActionWarn,
+		"C0BOTONLY": StaleActionWarn,
+		"C0YOUNGER": StaleActionWarn,
+		"C0PENDING": StaleActionPending,
+		"C0DUE0001": StaleActionArchive,
+		"C0USERDUE": StaleActionArchive,
+	}
+
+	if diff := pretty.Compare(gotActions, wantActions); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+
+	if len(report.Failed) != 0 {
+		t.Fatalf("expected no failures, got %v", report.Failed)
+	}
+
+	var warned, archived []string
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		var blocks []map[string]interface{}
+		_ = json.Unmarshal([]byte(r.FormValue("blocks")), &blocks)
+		if len(blocks) != 1 || blocks[0]["block_id"] != staleWarningBlockID {
+			t.Errorf("expected warning to be marked with block id %s, got blocks: %s", staleWarningBlockID, r.FormValue("blocks"))
+		}
+		warned = append(warned, r.FormValue("channel"))
+		_, _ = w.Write([]byte(mockPostMsgResp))
+	})
+	mux.HandleFunc("/conversations.archive", func(w http.ResponseWriter, r *http.Request) {
+		archived = append(archived, r.FormValue("channel"))
+		_, _ = w.Write([]byte(mockSuccessResp))
+	})
+
+	result, err := channel.ApplyStalePolicy(report, policy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Strings(warned)
+	if diff := pretty.Compare(warned, []string{"C0BOTONLY", "C0IDLE001", "C0SILENT1", "C0YOUNGER"}); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+
+	sort.Strings(archived)
+	if diff := pretty.Compare(archived, []string{"C0DUE0001", "C0USERDUE"}); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+
+	if len(result.Warned.Succeeded) != 4 || len(result.Archived.Succeeded) != 2 {
+		t.Fatalf("expected 4 warned and 2 archived, got %v warned and %v archived", len(result.Warned.Succeeded), len(result.Archived.Succeeded))
+	}
+}
+
+func TestScanStaleChannelsHistoryFailure(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/conversations.list", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(mockConversationsListLastPageResp))
+	})
+	mux.HandleFunc("/conversations.history", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(mockConversationMembersErrResp))
+	})
+
+	testServ := httptest.NewServer(mux)
+	defer testServ.Close()
+
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+	channel := Channel{
+		UserClient: client,
+	}
+
+	report, err := channel.ScanStaleChannels(StalePolicy{ArchiveAfter: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(report.Channels) != 0 {
+		t.Fatalf("expected no stale channels, got %v", report.Channels)
+	}
+
+	if len(report.Failed) != 1 || report.Failed[0].ID != "C061EG9T2" || report.Failed[0].Err.Error() != "channel_not_found" {
+		t.Fatalf("expected history failure for C061EG9T2, got %v", report.Failed)
+	}
+}
+
+func TestTsToTime(t *testing.T) {
+	testCases := []struct {
+		ts       string
+		wantTime time.Time
+	}{
+		{ts: "1503435956.000247", wantTime: time.Unix(1503435956, 247000)},
+		{ts: "1503435956", wantTime: time.Unix(1503435956, 0)},
+		{ts: "not a timestamp", wantTime: time.Time{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.ts, func(t *testing.T) {
+			if got := tsToTime(tc.ts); !got.Equal(tc.wantTime) {
+				t.Fatalf("expected time: %v, got: %v", tc.wantTime, got)
+			}
+		})
+	}
+}
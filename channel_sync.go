@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"github.com/pkg/errors"
+)
+
+const errCantKickSelfMsg = "cant_kick_self"
+
+// SyncOpts configures how Channel.SyncMembers reconciles channel membership
+type SyncOpts struct {
+	// RemoveExtra removes members who are not in the desired list
+	RemoveExtra bool
+	// Protected members are never removed (e.g. the bot user)
+	Protected []string
+	// DryRun only computes the plan without inviting or removing anyone
+	DryRun bool
+}
+
+// SyncPlan lists the membership changes needed to match the desired members
+type SyncPlan struct {
+	Invite []string
+	Remove []string
+}
+
+// SyncResult holds the plan computed by SyncMembers and, unless it was a dry
+// run, the outcome of carrying it out. Removed is nil if RemoveExtra was not set.
+type SyncResult struct {
+	Plan    SyncPlan
+	Invited *BulkResult
+	Removed *BulkResult
+}
+
+// SyncMembers reconciles the channel's membership with the desired list of
+// user IDs, inviting anyone missing and optionally removing extra members.
+// Use DryRun to review the plan (e.g. in an approval step) before applying it.
+func (c *Channel) SyncMembers(desired []string, opts SyncOpts) (*SyncResult, error) {
+	if c.UserClient == nil {
+		return nil, errors.New("method requires user client")
+	}
+
+	current, err := GetChannelMembers(c.UserClient, c.ChannelID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get channel members")
+	}
+
+	result := &SyncResult{Plan: planSync(current, desired, opts)}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	result.Invited, err = c.InviteUsers(result.Plan.Invite)
+	if err != nil {
+		return result, errors.Wrapf(err, "failed to invite members")
+	}
+
+	if opts.RemoveExtra {
+		result.Removed, err = c.RemoveMembers(result.Plan.Remove)
+		if err != nil {
+			return result, errors.Wrapf(err, "failed to remove members")
+		}
+	}
+
+	return result, nil
+}
+
+// RemoveMembers removes multiple users from the channel. Attempts to remove
+// users who are not members, or the calling user themselves, are reported as
+// skipped.
+func (c *Channel) RemoveMembers(userIDs []string) (*BulkResult, error) {
+	if c.UserClient == nil {
+		return nil, errors.New("method requires user client")
+	}
+
+	kick := func(userID string) error {
+		return c.UserClient.KickUserFromConversation(c.ChannelID, userID)
+	}
+
+	return c.runBulk(userIDs, kick, errNotInChannelMsg, errCantKickSelfMsg)
+}
+
+// RemoveMember removes a single user from the channel. Removing a user who is
+// not a member, or the calling user themselves, is not treated as an error.
+func (c *Channel) RemoveMember(userID string) error {
+	if c.UserClient == nil {
+		return errors.New("method requires user client")
+	}
+
+	err := c.UserClient.KickUserFromConversation(c.ChannelID, userID)
+	if err != nil && err.Error() != errNotInChannelMsg && err.Error() != errCantKickSelfMsg {
+		return err
+	}
+
+	return nil
+}
+
+func planSync(current, desired []string, opts SyncOpts) SyncPlan {
+	var plan SyncPlan
+
+	currentSet := toSet(current)
+	desiredSet := make(map[string]struct{}, len(desired))
+	for _, id := range desired {
+		if _, ok := desiredSet[id]; ok {
+			continue
+		}
+		desiredSet[id] = struct{}{}
+		if _, ok := currentSet[id]; !ok {
+			plan.Invite = append(plan.Invite, id)
+		}
+	}
+
+	if !opts.RemoveExtra {
+		return plan
+	}
+
+	protectedSet := toSet(opts.Protected)
+	for _, id := range current {
+		_, wanted := desiredSet[id]
+		_, protected := protectedSet[id]
+		if !wanted && !protected {
+			plan.Remove = append(plan.Remove, id)
+		}
+	}
+
+	return plan
+}
+
+func toSet(ids []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/slack-go/slack"
+)
+
+func TestSyncMembers(t *testing.T) {
+	testCases := []struct {
+		description string
+		desired     []string
+		opts        SyncOpts
+		respMembers []byte
+		respKick    []byte
+		wantPlan    SyncPlan
+		wantInvited []string
+		wantKicked  []string
+		wantErr     string
+	}{
+		{
+			description: "missing members are invited, extra members are kept by default",
+			desired:     []string{"U0G9QF9C6", "UABC123EFG", "UABC123EFG"},
+			respMembers: []byte(mockConversationMembersResp),
+			wantPlan:    SyncPlan{Invite: []string{"UABC123EFG"}},
+			wantInvited: []string{"UABC123EFG"},
+		},
+		{
+			description: "extra members are removed when requested",
+			desired:     []string{"UABC123EFG"},
+			opts:        SyncOpts{RemoveExtra: true},
+			respMembers: []byte(mockConversationMembersResp),
+			respKick:    []byte(mockSuccessResp),
+			wantPlan: SyncPlan{
+				Invite: []string{"UABC123EFG"},
+				Remove: []string{"U0G9QF9C6", "U1QNSQB9U"},
+			},
+			wantInvited: []string{"UABC123EFG"},
+			wantKicked:  []string{"U0G9QF9C6", "U1QNSQB9U"},
+		},
+		{
+			description: "protected members are never removed",
+			desired:     []string{"U0G9QF9C6"},
+			opts:        SyncOpts{RemoveExtra: true, Protected: []string{"U1QNSQB9U"}},
+			respMembers: []byte(mockConversationMembersResp),
+			respKick:    []byte(mockSuccessResp),
+			wantPlan:    SyncPlan{},
+		},
+		{
+			description: "dry run returns the plan without making changes",
+			desired:     []string{"UABC123EFG"},
+			opts:        SyncOpts{RemoveExtra: true, DryRun: true},
+			respMembers: []byte(mockConversationMembersResp),
+			wantPlan: SyncPlan{
+				Invite: []string{"UABC123EFG"},
+				Remove: []string{"U0G9QF9C6", "U1QNSQB9U"},
+			},
+		},
+		{
+			description: "failure to remove members",
+			desired:     []string{"U0G9QF9C6"},
+			opts:        SyncOpts{RemoveExtra: true},
+			respMembers: []byte(mockConversationMembersResp),
+			respKick:    []byte(mockKickErrResp),
+			wantPlan:    SyncPlan{Remove: []string{"U1QNSQB9U"}},
+			wantKicked:  []string{"U1QNSQB9U"},
+			wantErr:     "failed to remove members: restricted_action",
+		},
+		{
+			description: "failure to retrieve current members",
+			desired:     []string{"U0G9QF9C6"},
+			respMembers: []byte(mockConversationMembersErrResp),
+			wantErr:     "failed to get channel members: channel_not_found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var invited, kicked []string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/conversations.members", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(tc.respMembers)
+			})
+			mux.HandleFunc("/conversations.invite", func(w http.ResponseWriter, r *http.Request) {
+				invited = append(invited, r.FormValue("users"))
+				_, _ = w.Write([]byte(mockInviteMembersResp))
+			})
+			mux.HandleFunc("/conversations.kick", func(w http.ResponseWriter, r *http.Request) {
+				kicked = append(kicked, r.FormValue("user"))
+				_, _ = w.Write(tc.respKick)
+			})
+
+			testServ := httptest.NewServer(mux)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+			channel := Channel{
+				UserClient: client,
+				ChannelID:  "C1H9RESGL",
+			}
+
+			result, err := channel.SyncMembers(tc.desired, tc.opts)
+
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+			}
+
+			if result != nil {
+				if diff := pretty.Compare(result.Plan, tc.wantPlan); diff != "" {
+					t.Fatalf("-got +want %s\n", diff)
+				}
+			}
+
+			if diff := pretty.Compare(invited, tc.wantInvited); diff != "" {
+				t.Fatalf("-got +want %s\n", diff)
+			}
+
+			if diff := pretty.Compare(kicked, tc.wantKicked); diff != "" {
+				t.Fatalf("-got +want %s\n", diff)
+			}
+		})
+	}
+}
+
+func TestRemoveMember(t *testing.T) {
+	testCases := []struct {
+		description string
+		respKick    []byte
+		wantErr     string
+	}{
+		{
+			description: "successfully removed member",
+			respKick:    []byte(mockSuccessResp),
+		},
+		{
+			description: "no error returned for user not in channel",
+			respKick:    []byte(mockNotInChannelResp),
+		},
+		{
+			description: "failure to remove member",
+			respKick:    []byte(mockKickErrResp),
+			wantErr:     "restricted_action",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var gotChannel, gotUser string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/conversations.kick", func(w http.ResponseWriter, r *http.Request) {
+				gotChannel, gotUser = r.FormValue("channel"), r.FormValue("user")
+				_, _ = w.Write(tc.respKick)
+			})
+
+			testServ := httptest.NewServer(mux)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+			channel := Channel{
+				UserClient: client,
+				ChannelID:  "C0DEL09A5",
+			}
+
+			err := channel.RemoveMember("U1QNSQB9U")
+
+			if gotChannel != "C0DEL09A5" || gotUser != "U1QNSQB9U" {
+				t.Fatalf("expected kick of U1QNSQB9U from C0DEL09A5, got %s from %s", gotUser, gotChannel)
+			}
+
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const bookmarkTypeLink = "link"
+
+// ChannelTemplate declares everything needed to set up a new channel (e.g.
+// for an incident or project) so it can be created in a single call to
+// Channel.CreateFromTemplate. Only Name is required.
+type ChannelTemplate struct {
+	Name    string
+	Topic   string
+	Purpose string
+	// Members are invited to the channel once it has been created
+	Members []string
+	// InitMsg is posted once members have been invited, and pinned to the
+	// channel if PinInitMsg is set
+	InitMsg    Msg
+	PinInitMsg bool
+	Bookmarks  []Bookmark
+}
+
+// Bookmark is a link added to the channel's bookmarks bar
+type Bookmark struct {
+	Title string
+	Link  string
+	Emoji string
+}
+
+// CreateFromTemplate opens a new channel and applies the template to it. If
+// any step after the channel was opened fails, the channel is archived again
+// so no half set up channel is left behind. Setting the topic, purpose,
+// pins and bookmarks is done with the UserClient, which must therefore have
+// the pins:write and bookmarks:write scopes as required by the template.
+func (c *Channel) CreateFromTemplate(tmpl ChannelTemplate) error {
+	if c.UserClient == nil {
+		return errors.New("method requires user client")
+	}
+
+	channel, err := c.createConversation(tmpl.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create new channel")
+	}
+
+	if err := c.applyTemplate(channel.ID, tmpl); err != nil {
+		if archiveErr := c.UserClient.ArchiveConversation(channel.ID); archiveErr != nil {
+			return errors.Wrapf(err, "failed to archive channel %s after setup failed (%v)", channel.ID, archiveErr)
+		}
+		return err
+	}
+
+	c.ChannelID = channel.ID
+
+	return nil
+}
+
+func (c *Channel) applyTemplate(channelID string, tmpl ChannelTemplate) error {
+	if tmpl.Topic != "" {
+		if _, err := c.UserClient.SetTopicOfConversation(channelID, tmpl.Topic); err != nil {
+			return errors.Wrapf(err, "failed to set topic")
+		}
+	}
+
+	if tmpl.Purpose != "" {
+		if _, err := c.UserClient.SetPurposeOfConversation(channelID, tmpl.Purpose); err != nil {
+			return errors.Wrapf(err, "failed to set purpose")
+		}
+	}
+
+	if _, err := c.inviteUsers(channelID, tmpl.Members); err != nil {
+		return errors.Wrapf(err, "failed to invite user to channel")
+	}
+
+	if tmpl.InitMsg.Body != "" || len(tmpl.InitMsg.Blocks) > 0 {
+		ts, err := c.postChannelMsg(channelID, tmpl.InitMsg)
+		if err != nil {
+			return errors.Wrapf(err, "failed to post message")
+		}
+
+		if tmpl.PinInitMsg {
+			if err := c.UserClient.AddPin(channelID, slack.NewRefToMessage(channelID, ts)); err != nil {
+				return errors.Wrapf(err, "failed to pin message")
+			}
+		}
+	}
+
+	for _, bookmark := range tmpl.Bookmarks {
+		_, err := c.UserClient.AddBookmark(channelID, slack.AddBookmarkParameters{
+			Title: bookmark.Title,
+			Type:  bookmarkTypeLink,
+			Link:  bookmark.Link,
+			Emoji: bookmark.Emoji,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to add bookmark %q", bookmark.Title)
+		}
+	}
+
+	return nil
+}
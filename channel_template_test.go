@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/slack-go/slack"
+)
+
+func TestCreateFromTemplate(t *testing.T) {
+	tmpl := ChannelTemplate{
+		Name:       "Incident 42",
+		Topic:      "Database outage",
+		Purpose:    "Coordinate the response to incident 42",
+		Members:    []string{"UABC123EFG"},
+		InitMsg:    Msg{Body: "Incident declared"},
+		PinInitMsg: true,
+		Bookmarks: []Bookmark{
+			{Title: "Runbook", Link: "https://example.com/runbook"},
+			{Title: "Dashboard", Link: "https://example.com/dashboard", Emoji: ":chart:"},
+		},
+	}
+
+	testCases := []struct {
+		description       string
+		respChannelCreate []byte
+		respPin           []byte
+		respBookmark      []byte
+		respArchive       []byte
+		wantCalls         []string
+		wantID            string
+		wantErr           string
+	}{
+		{
+			description:       "successful channel creation from template",
+			respChannelCreate: []byte(mockChannelCreateResp),
+			respPin:           []byte(mockSuccessResp),
+			respBookmark:      []byte(mockSuccessResp),
+			wantCalls: []string{
+				"conversations.create incident-42",
+				"conversations.setTopic Database outage",
+				"conversations.setPurpose Coordinate the response to incident 42",
+				"conversations.invite UABC123EFG",
+				"chat.postMessage Incident declared",
+				"pins.add 1503435956.000247",
+				"bookmarks.add Runbook",
+				"bookmarks.add Dashboard",
+			},
+			wantID: "C0DEL09A5",
+		},
+		{
+			description:       "err creating channel, nothing to roll back",
+			respChannelCreate: []byte(mockChannelCreateErrResp),
+			wantCalls:         []string{"conversations.create incident-42"},
+			wantErr:           "failed to create new channel: invalid_name_specials",
+		},
+		{
+			description:       "err pinning message, channel is archived",
+			respChannelCreate: []byte(mockChannelCreateResp),
+			respPin:           []byte(mockPinErrResp),
+			respArchive:       []byte(mockSuccessResp),
+			wantCalls: []string{
+				"conversations.create incident-42",
+				"conversations.setTopic Database outage",
+				"conversations.setPurpose Coordinate the response to incident 42",
+				"conversations.invite UABC123EFG",
+				"chat.postMessage Incident declared",
+				"pins.add 1503435956.000247",
+				"conversations.archive C0DEL09A5",
+			},
+			wantErr: "failed to pin message: not_pinnable",
+		},
+		{
+			description:       "err adding bookmark and err archiving channel",
+			respChannelCreate: []byte(mockChannelCreateResp),
+			respPin:           []byte(mockSuccessResp),
+			respBookmark:      []byte(mockBookmarkErrResp),
+			respArchive:       []byte(mockChannelsArchiveErrResp),
+			wantCalls: []string{
+				"conversations.create incident-42",
+				"conversations.setTopic Database outage",
+				"conversations.setPurpose Coordinate the response to incident 42",
+				"conversations.invite UABC123EFG",
+				"chat.postMessage Incident declared",
+				"pins.add 1503435956.000247",
+				"bookmarks.add Runbook",
+				"conversations.archive C0DEL09A5",
+			},
+			wantErr: "failed to archive channel C0DEL09A5 after setup failed (invalid_auth): failed to add bookmark \"Runbook\": invalid_link",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var calls []string
+			handle := func(mux *http.ServeMux, method, param string, resp []byte) {
+				mux.HandleFunc("/"+method, func(w http.ResponseWriter, r *http.Request) {
+					calls = append(calls, fmt.Sprintf("%s %s", method, r.FormValue(param)))
+					_, _ = w.Write(resp)
+				})
+			}
+
+			mux := http.NewServeMux()
+			handle(mux, "conversations.create", "name", tc.respChannelCreate)
+			handle(mux, "conversations.setTopic", "topic", []byte(mockChannelCreateResp))
+			handle(mux, "conversations.setPurpose", "purpose", []byte(mockChannelCreateResp))
+			handle(mux, "conversations.invite", "users", []byte(mockInviteMembersResp))
+			handle(mux, "chat.postMessage", "text", []byte(mockPostMsgResp))
+			handle(mux, "pins.add", "timestamp", tc.respPin)
+			handle(mux, "bookmarks.add", "title", tc.respBookmark)
+			handle(mux, "conversations.archive", "channel", tc.respArchive)
+
+			testServ := httptest.NewServer(mux)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+			channel := Channel{
+				UserClient: client,
+			}
+
+			err := channel.CreateFromTemplate(tmpl)
+
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+			}
+
+			if diff := pretty.Compare(calls, tc.wantCalls); diff != "" {
+				t.Fatalf("-got +want %s\n", diff)
+			}
+
+			if channel.ChannelID != tc.wantID {
+				t.Fatalf("expected channel id: %s, got: %s", tc.wantID, channel.ChannelID)
+			}
+		})
+	}
+}
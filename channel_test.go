@@ -4,19 +4,27 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/kylelemons/godebug/pretty"
 	"github.com/slack-go/slack"
 )
 
 func TestCreateChannel(t *testing.T) {
 	testCases := []struct {
 		description       string
+		channelName       string
+		isPrivate         bool
+		nameSuffix        ChannelNameSuffix
+		takenNames        []string
 		inviteMembers     []string
 		initMsg           Msg
 		respChannelCreate []byte
 		respInviteMembers []byte
 		respPostMsg       []byte
+		wantName          string
 		wantID            string
 		wantErr           string
 	}{
@@ -27,6 +35,41 @@ func TestCreateChannel(t *testing.T) {
 			respChannelCreate: []byte(mockChannelCreateResp),
 			wantID:            "C0DEL09A5",
 		},
+		{
+			description:       "successful private channel creation",
+			isPrivate:         true,
+			inviteMembers:     []string{},
+			initMsg:           Msg{},
+			respChannelCreate: []byte(mockChannelCreateResp),
+			wantID:            "C0DEL09A5",
+		},
+		{
+			description:       "channel name is normalized before creation",
+			channelName:       "General Chat!",
+			respChannelCreate: []byte(mockChannelCreateResp),
+			wantName:          "general-chat",
+			wantID:            "C0DEL09A5",
+		},
+		{
+			description:       "taken channel name is retried with suffix",
+			nameSuffix:        NumberedSuffix,
+			takenNames:        []string{"general", "general-2"},
+			respChannelCreate: []byte(mockChannelCreateResp),
+			wantName:          "general-3",
+			wantID:            "C0DEL09A5",
+		},
+		{
+			description:       "err on taken channel name when no suffix is configured",
+			takenNames:        []string{"general"},
+			respChannelCreate: []byte(mockChannelCreateResp),
+			wantName:          "general",
+			wantErr:           "failed to create new channel: name_taken",
+		},
+		{
+			description: "err on channel name without any valid characters",
+			channelName: "!!!",
+			wantErr:     "failed to create new channel: channel name is empty after normalization",
+		},
 		{
 			description:       "err creating channel",
 			inviteMembers:     []string{},
@@ -80,11 +123,20 @@ func TestCreateChannel(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
+			var gotName, gotPrivate string
 			mux := http.NewServeMux()
-			mux.HandleFunc("/channels.create", func(w http.ResponseWriter, r *http.Request) {
+			mux.HandleFunc("/conversations.create", func(w http.ResponseWriter, r *http.Request) {
+				gotName = r.FormValue("name")
+				gotPrivate = r.FormValue("is_private")
+				for _, taken := range tc.takenNames {
+					if gotName == taken {
+						_, _ = w.Write([]byte(mockNameTakenErrResp))
+						return
+					}
+				}
 				_, _ = w.Write(tc.respChannelCreate)
 			})
-			mux.HandleFunc("/channels.invite", func(w http.ResponseWriter, r *http.Request) {
+			mux.HandleFunc("/conversations.invite", func(w http.ResponseWriter, r *http.Request) {
 				_, _ = w.Write(tc.respInviteMembers)
 			})
 			mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
@@ -97,9 +149,24 @@ func TestCreateChannel(t *testing.T) {
 			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
 			channel := Channel{
 				UserClient: client,
+				IsPrivate:  tc.isPrivate,
+				NameSuffix: tc.nameSuffix,
+			}
+
+			channelName := tc.channelName
+			if channelName == "" {
+				channelName = "general"
 			}
 
-			err := channel.CreateChannel("general", tc.inviteMembers, tc.initMsg)
+			err := channel.CreateChannel(channelName, tc.inviteMembers, tc.initMsg)
+
+			if gotName != "" && gotPrivate != fmt.Sprint(tc.isPrivate) {
+				t.Fatalf("expected is_private: %v, got: %s", tc.isPrivate, gotPrivate)
+			}
+
+			if tc.wantName != "" && gotName != tc.wantName {
+				t.Fatalf("expected channel name: %s, got: %s", tc.wantName, gotName)
+			}
 
 			if tc.wantErr == "" && err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -122,34 +189,126 @@ func TestCreateChannel(t *testing.T) {
 }
 
 func TestInviteUsers(t *testing.T) {
+	batchIDs := mockUserIDs(inviteBatchSize + 1)
+	mixedIDs := []string{"UABC123EFG", "U0G9QF9C6", "U1QNSQB9U", "UBAD12345", "UXYZ98765"}
+	mixedResps := map[string][]byte{
+		"UABC123EFG": []byte(mockInviteMembersResp),
+		"U0G9QF9C6":  []byte(mockCantInviteSelfErrResp),
+		"U1QNSQB9U":  []byte(mockAlreadyInChannelErrResp),
+		"UBAD12345":  []byte(mockInviteMembersErrResp),
+		"UXYZ98765":  []byte(mockInviteMembersResp),
+	}
+
 	testCases := []struct {
 		description       string
 		inviteMembers     []string
+		continueOnError   bool
 		respInviteMembers []byte
+		respByUser        map[string][]byte
+		rateLimitedTimes  int
+		wantCalls         int
+		wantResult        wantBulkResult
 		wantErr           string
 	}{
 		{
 			description:       "successful invite of users",
 			inviteMembers:     []string{"UABC123EFG"},
 			respInviteMembers: []byte(mockInviteMembersResp),
+			wantResult:        wantBulkResult{succeeded: []string{"UABC123EFG"}},
 		},
 		{
 			description:       "successful invite, no error returned for invite members resp",
 			inviteMembers:     []string{"UABC123EFG", "U0G9QF9C6"},
 			respInviteMembers: []byte(mockInviteMembersResp),
+			wantResult:        wantBulkResult{succeeded: []string{"UABC123EFG", "U0G9QF9C6"}},
+		},
+		{
+			description:       "successful invite of more users than fit in a single request",
+			inviteMembers:     batchIDs,
+			respInviteMembers: []byte(mockInviteMembersResp),
+			wantCalls:         2,
+			wantResult:        wantBulkResult{succeeded: batchIDs},
+		},
+		{
+			description:       "inviting self is reported as skipped",
+			inviteMembers:     []string{"U0G9QF9C6"},
+			respInviteMembers: []byte(mockCantInviteSelfErrResp),
+			wantResult: wantBulkResult{
+				skipped: []BulkSkipped{{ID: "U0G9QF9C6", Reason: "cant_invite_self"}},
+			},
 		},
 		{
 			description:       "expect error",
 			inviteMembers:     []string{"UABC123EFG"},
 			respInviteMembers: []byte(mockInviteMembersErrResp),
+			wantResult:        wantBulkResult{failed: []string{"UABC123EFG"}},
 			wantErr:           "cant_invite",
 		},
+		{
+			description:       "failed batch is retried per user, stopping at the first failure",
+			inviteMembers:     mixedIDs,
+			respInviteMembers: []byte(mockInviteMembersErrResp),
+			respByUser:        mixedResps,
+			wantCalls:         5,
+			wantResult: wantBulkResult{
+				succeeded: []string{"UABC123EFG"},
+				skipped: []BulkSkipped{
+					{ID: "U0G9QF9C6", Reason: "cant_invite_self"},
+					{ID: "U1QNSQB9U", Reason: "already_in_channel"},
+				},
+				failed: []string{"UBAD12345"},
+			},
+			wantErr: "cant_invite",
+		},
+		{
+			description:       "failed batch is retried per user, continuing past failures",
+			inviteMembers:     mixedIDs,
+			continueOnError:   true,
+			respInviteMembers: []byte(mockInviteMembersErrResp),
+			respByUser:        mixedResps,
+			wantCalls:         6,
+			wantResult: wantBulkResult{
+				succeeded: []string{"UABC123EFG", "UXYZ98765"},
+				skipped: []BulkSkipped{
+					{ID: "U0G9QF9C6", Reason: "cant_invite_self"},
+					{ID: "U1QNSQB9U", Reason: "already_in_channel"},
+				},
+				failed: []string{"UBAD12345"},
+			},
+		},
+		{
+			description:       "batch failing for the channel is not retried per user",
+			inviteMembers:     mixedIDs,
+			continueOnError:   true,
+			respInviteMembers: []byte(mockConversationMembersErrResp),
+			wantCalls:         1,
+			wantResult:        wantBulkResult{failed: mixedIDs},
+		},
+		{
+			description:       "rate limited batch is retried",
+			inviteMembers:     mixedIDs,
+			rateLimitedTimes:  1,
+			respInviteMembers: []byte(mockInviteMembersResp),
+			wantCalls:         2,
+			wantResult:        wantBulkResult{succeeded: mixedIDs},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
+			var calls int
 			mux := http.NewServeMux()
-			mux.HandleFunc("/channels.invite", func(w http.ResponseWriter, r *http.Request) {
+			mux.HandleFunc("/conversations.invite", func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= tc.rateLimitedTimes {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				if resp, ok := tc.respByUser[r.FormValue("users")]; ok {
+					_, _ = w.Write(resp)
+					return
+				}
 				_, _ = w.Write(tc.respInviteMembers)
 			})
 
@@ -158,11 +317,12 @@ func TestInviteUsers(t *testing.T) {
 
 			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
 			channel := Channel{
-				UserClient: client,
-				ChannelID:  "C1H9RESGL",
+				UserClient:      client,
+				ChannelID:       "C1H9RESGL",
+				ContinueOnError: tc.continueOnError,
 			}
 
-			err := channel.InviteUsers(tc.inviteMembers)
+			result, err := channel.InviteUsers(tc.inviteMembers)
 
 			if tc.wantErr == "" && err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -176,6 +336,12 @@ func TestInviteUsers(t *testing.T) {
 					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
 				}
 			}
+
+			if tc.wantCalls != 0 && calls != tc.wantCalls {
+				t.Fatalf("expected %v invite requests, got %v", tc.wantCalls, calls)
+			}
+
+			checkBulkResult(t, result, tc.wantResult)
 		})
 	}
 }
@@ -183,27 +349,38 @@ func TestInviteUsers(t *testing.T) {
 func TestGetChannelMembers(t *testing.T) {
 	testCases := []struct {
 		description     string
-		respChannelInfo []byte
+		respMembers     []byte
+		respMembersNext []byte
 		wantErr         string
 		wantIDs         []string
 	}{
 		{
-			description:     "successful retrieval of member IDs",
-			respChannelInfo: []byte(mockChannelInfoResp),
+			description: "successful retrieval of member IDs",
+			respMembers: []byte(mockConversationMembersResp),
+			wantIDs:     []string{"U0G9QF9C6", "U1QNSQB9U"},
+		},
+		{
+			description:     "successful retrieval of member IDs across multiple pages",
+			respMembers:     []byte(mockConversationMembersFirstPageResp),
+			respMembersNext: []byte(mockConversationMembersLastPageResp),
 			wantIDs:         []string{"U0G9QF9C6", "U1QNSQB9U"},
 		},
 		{
-			description:     "failure to retrieve member IDs",
-			respChannelInfo: []byte(mockChannelInfoErrResp),
-			wantErr:         "channel_not_found",
+			description: "failure to retrieve member IDs",
+			respMembers: []byte(mockConversationMembersErrResp),
+			wantErr:     "channel_not_found",
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
 			mux := http.NewServeMux()
-			mux.HandleFunc("/channels.info", func(w http.ResponseWriter, r *http.Request) {
-				_, _ = w.Write(tc.respChannelInfo)
+			mux.HandleFunc("/conversations.members", func(w http.ResponseWriter, r *http.Request) {
+				if r.FormValue("cursor") != "" {
+					_, _ = w.Write(tc.respMembersNext)
+					return
+				}
+				_, _ = w.Write(tc.respMembers)
 			})
 
 			testServ := httptest.NewServer(mux)
@@ -241,37 +418,37 @@ func TestGetChannelMembers(t *testing.T) {
 
 func TestGetChannelMemberEmails(t *testing.T) {
 	testCases := []struct {
-		description     string
-		respChannelInfo []byte
-		respUsersList   []byte
-		wantErr         string
-		wantEmails      []string
+		description   string
+		respMembers   []byte
+		respUsersList []byte
+		wantErr       string
+		wantEmails    []string
 	}{
 		{
-			description:     "successful retrieval of member emails",
-			respChannelInfo: []byte(mockChannelInfoResp),
-			respUsersList:   []byte(mockUsersListResp),
-			wantEmails:      []string{"spengler@ghostbusters.example.com"},
+			description:   "successful retrieval of member emails",
+			respMembers:   []byte(mockConversationMembersResp),
+			respUsersList: []byte(mockUsersListResp),
+			wantEmails:    []string{"spengler@ghostbusters.example.com"},
 		},
 		{
-			description:     "failure to retrieve channel info",
-			respChannelInfo: []byte(mockChannelInfoErrResp),
-			respUsersList:   []byte(mockUsersListResp),
-			wantErr:         "channel_not_found",
+			description:   "failure to retrieve channel info",
+			respMembers:   []byte(mockConversationMembersErrResp),
+			respUsersList: []byte(mockUsersListResp),
+			wantErr:       "channel_not_found",
 		},
 		{
-			description:     "failure to retrieve user list",
-			respChannelInfo: []byte(mockChannelInfoResp),
-			respUsersList:   []byte(mockUsersListErrResp),
-			wantErr:         "invalid_cursor",
+			description:   "failure to retrieve user list",
+			respMembers:   []byte(mockConversationMembersResp),
+			respUsersList: []byte(mockUsersListErrResp),
+			wantErr:       "invalid_cursor",
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
 			mux := http.NewServeMux()
-			mux.HandleFunc("/channels.info", func(w http.ResponseWriter, r *http.Request) {
-				_, _ = w.Write(tc.respChannelInfo)
+			mux.HandleFunc("/conversations.members", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(tc.respMembers)
 			})
 			mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
 				_, _ = w.Write(tc.respUsersList)
@@ -313,25 +490,58 @@ func TestGetChannelMemberEmails(t *testing.T) {
 func TestLeaveChannels(t *testing.T) {
 	testCases := []struct {
 		description       string
-		respLeaveChannels []byte
+		continueOnError   bool
+		respLeaveChannels map[string][]byte
+		wantResult        wantBulkResult
 		wantErr           string
 	}{
 		{
-			description:       "successfully left channels",
-			respLeaveChannels: []byte(mockSuccessResp),
+			description: "successfully left channels",
+			respLeaveChannels: map[string][]byte{
+				"C1H9RESGL": []byte(mockSuccessResp),
+				"C0DEL09A5": []byte(mockSuccessResp),
+			},
+			wantResult: wantBulkResult{succeeded: []string{"C1H9RESGL", "C0DEL09A5"}},
+		},
+		{
+			description: "channel the user is not in is reported as skipped",
+			respLeaveChannels: map[string][]byte{
+				"C1H9RESGL": []byte(mockNotInChannelResp),
+				"C0DEL09A5": []byte(mockSuccessResp),
+			},
+			wantResult: wantBulkResult{
+				succeeded: []string{"C0DEL09A5"},
+				skipped:   []BulkSkipped{{ID: "C1H9RESGL", Reason: "not_in_channel"}},
+			},
 		},
 		{
-			description:       "failure to leave channels",
-			respLeaveChannels: []byte(mockChannelsLeaveErrResp),
-			wantErr:           "invalid_auth",
+			description: "failure to leave channels",
+			respLeaveChannels: map[string][]byte{
+				"C1H9RESGL": []byte(mockChannelsLeaveErrResp),
+				"C0DEL09A5": []byte(mockSuccessResp),
+			},
+			wantResult: wantBulkResult{failed: []string{"C1H9RESGL"}},
+			wantErr:    "invalid_auth",
+		},
+		{
+			description:     "failure to leave channels, continuing past failures",
+			continueOnError: true,
+			respLeaveChannels: map[string][]byte{
+				"C1H9RESGL": []byte(mockChannelsLeaveErrResp),
+				"C0DEL09A5": []byte(mockSuccessResp),
+			},
+			wantResult: wantBulkResult{
+				succeeded: []string{"C0DEL09A5"},
+				failed:    []string{"C1H9RESGL"},
+			},
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
 			mux := http.NewServeMux()
-			mux.HandleFunc("/channels.leave", func(w http.ResponseWriter, r *http.Request) {
-				_, _ = w.Write(tc.respLeaveChannels)
+			mux.HandleFunc("/conversations.leave", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(tc.respLeaveChannels[r.FormValue("channel")])
 			})
 
 			testServ := httptest.NewServer(mux)
@@ -339,10 +549,11 @@ func TestLeaveChannels(t *testing.T) {
 
 			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
 			channel := Channel{
-				UserClient: client,
+				UserClient:      client,
+				ContinueOnError: tc.continueOnError,
 			}
 
-			err := channel.LeaveChannels([]string{"C1H9RESGL"})
+			result, err := channel.LeaveChannels([]string{"C1H9RESGL", "C0DEL09A5"})
 
 			if tc.wantErr == "" && err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -356,6 +567,8 @@ func TestLeaveChannels(t *testing.T) {
 					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
 				}
 			}
+
+			checkBulkResult(t, result, tc.wantResult)
 		})
 	}
 }
@@ -363,29 +576,131 @@ func TestLeaveChannels(t *testing.T) {
 func TestArchiveChannels(t *testing.T) {
 	testCases := []struct {
 		description         string
-		respArchiveChannels []byte
+		continueOnError     bool
+		respArchiveChannels map[string][]byte
+		wantResult          wantBulkResult
 		wantErr             string
 	}{
 		{
-			description:         "successfully archived channels",
-			respArchiveChannels: []byte(mockSuccessResp),
+			description: "successfully archived channels",
+			respArchiveChannels: map[string][]byte{
+				"C1H9RESGL": []byte(mockSuccessResp),
+				"C0DEL09A5": []byte(mockSuccessResp),
+			},
+			wantResult: wantBulkResult{succeeded: []string{"C1H9RESGL", "C0DEL09A5"}},
 		},
 		{
-			description:         "no error returned for already archived channel",
-			respArchiveChannels: []byte(mockChannelAlreadyArchivedErrResp),
+			description: "no error returned for already archived channel",
+			respArchiveChannels: map[string][]byte{
+				"C1H9RESGL": []byte(mockChannelAlreadyArchivedErrResp),
+				"C0DEL09A5": []byte(mockSuccessResp),
+			},
+			wantResult: wantBulkResult{
+				succeeded: []string{"C0DEL09A5"},
+				skipped:   []BulkSkipped{{ID: "C1H9RESGL", Reason: "already_archived"}},
+			},
 		},
 		{
-			description:         "failure to archive channels",
-			respArchiveChannels: []byte(mockChannelsArchiveErrResp),
-			wantErr:             "invalid_auth",
+			description: "failure to archive channels",
+			respArchiveChannels: map[string][]byte{
+				"C1H9RESGL": []byte(mockChannelsArchiveErrResp),
+				"C0DEL09A5": []byte(mockSuccessResp),
+			},
+			wantResult: wantBulkResult{failed: []string{"C1H9RESGL"}},
+			wantErr:    "invalid_auth",
+		},
+		{
+			description:     "failure to archive channels, continuing past failures",
+			continueOnError: true,
+			respArchiveChannels: map[string][]byte{
+				"C1H9RESGL": []byte(mockChannelsArchiveErrResp),
+				"C0DEL09A5": []byte(mockSuccessResp),
+			},
+			wantResult: wantBulkResult{
+				succeeded: []string{"C0DEL09A5"},
+				failed:    []string{"C1H9RESGL"},
+			},
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
 			mux := http.NewServeMux()
-			mux.HandleFunc("/channels.archive", func(w http.ResponseWriter, r *http.Request) {
-				_, _ = w.Write(tc.respArchiveChannels)
+			mux.HandleFunc("/conversations.archive", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(tc.respArchiveChannels[r.FormValue("channel")])
+			})
+
+			testServ := httptest.NewServer(mux)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+			channel := Channel{
+				UserClient:      client,
+				ContinueOnError: tc.continueOnError,
+			}
+
+			result, err := channel.ArchiveChannels([]string{"C1H9RESGL", "C0DEL09A5"})
+
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+			}
+
+			checkBulkResult(t, result, tc.wantResult)
+		})
+	}
+}
+
+func TestUnarchiveChannels(t *testing.T) {
+	testCases := []struct {
+		description           string
+		respUnarchiveChannels map[string][]byte
+		wantResult            wantBulkResult
+		wantErr               string
+	}{
+		{
+			description: "successfully unarchived channels",
+			respUnarchiveChannels: map[string][]byte{
+				"C1H9RESGL": []byte(mockSuccessResp),
+				"C0DEL09A5": []byte(mockSuccessResp),
+			},
+			wantResult: wantBulkResult{succeeded: []string{"C1H9RESGL", "C0DEL09A5"}},
+		},
+		{
+			description: "no error returned for channel that is not archived",
+			respUnarchiveChannels: map[string][]byte{
+				"C1H9RESGL": []byte(mockChannelNotArchivedErrResp),
+				"C0DEL09A5": []byte(mockSuccessResp),
+			},
+			wantResult: wantBulkResult{
+				succeeded: []string{"C0DEL09A5"},
+				skipped:   []BulkSkipped{{ID: "C1H9RESGL", Reason: "not_archived"}},
+			},
+		},
+		{
+			description: "failure to unarchive channels",
+			respUnarchiveChannels: map[string][]byte{
+				"C1H9RESGL": []byte(mockChannelsArchiveErrResp),
+				"C0DEL09A5": []byte(mockSuccessResp),
+			},
+			wantResult: wantBulkResult{failed: []string{"C1H9RESGL"}},
+			wantErr:    "invalid_auth",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/conversations.unarchive", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(tc.respUnarchiveChannels[r.FormValue("channel")])
 			})
 
 			testServ := httptest.NewServer(mux)
@@ -396,7 +711,7 @@ func TestArchiveChannels(t *testing.T) {
 				UserClient: client,
 			}
 
-			err := channel.ArchiveChannels([]string{"C1H9RESGL"})
+			result, err := channel.UnarchiveChannels([]string{"C1H9RESGL", "C0DEL09A5"})
 
 			if tc.wantErr == "" && err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -410,6 +725,247 @@ func TestArchiveChannels(t *testing.T) {
 					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
 				}
 			}
+
+			checkBulkResult(t, result, tc.wantResult)
 		})
 	}
 }
+
+func TestRenameChannel(t *testing.T) {
+	testCases := []struct {
+		description string
+		channelName string
+		nameSuffix  ChannelNameSuffix
+		takenNames  []string
+		respErr     []byte
+		wantName    string
+		wantErr     string
+	}{
+		{
+			description: "successful rename using normalized name",
+			channelName: "Incident 42: API Outage",
+			wantName:    "incident-42-api-outage",
+		},
+		{
+			description: "taken name retried with suffix",
+			channelName: "incident-42",
+			nameSuffix:  NumberedSuffix,
+			takenNames:  []string{"incident-42", "incident-42-2"},
+			wantName:    "incident-42-3",
+		},
+		{
+			description: "taken name without suffix configured",
+			channelName: "incident-42",
+			takenNames:  []string{"incident-42"},
+			wantErr:     "failed to rename channel: name_taken",
+		},
+		{
+			description: "name with no valid characters",
+			channelName: "!!!",
+			wantErr:     ErrInvalidChannelName.Error(),
+		},
+		{
+			description: "failure to rename channel",
+			channelName: "incident-42",
+			respErr:     []byte(mockChannelsArchiveErrResp),
+			wantErr:     "failed to rename channel: invalid_auth",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/conversations.rename", func(w http.ResponseWriter, r *http.Request) {
+				name := r.FormValue("name")
+				for _, taken := range tc.takenNames {
+					if name == taken {
+						_, _ = w.Write([]byte(mockNameTakenErrResp))
+						return
+					}
+				}
+				if tc.respErr != nil {
+					_, _ = w.Write(tc.respErr)
+					return
+				}
+				_, _ = fmt.Fprintf(w, `{"ok": true, "channel": {"id": %q, "name": %q}}`, r.FormValue("channel"), name)
+			})
+
+			testServ := httptest.NewServer(mux)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+			channel := Channel{
+				UserClient: client,
+				ChannelID:  "C0DEL09A5",
+				NameSuffix: tc.nameSuffix,
+			}
+
+			name, err := channel.RenameChannel(tc.channelName)
+
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+			}
+
+			if name != tc.wantName {
+				t.Fatalf("expected channel name: %s, got: %s", tc.wantName, name)
+			}
+		})
+	}
+}
+
+func TestArchiveChannelsConcurrently(t *testing.T) {
+	channelIDs := mockUserIDs(10)
+
+	var inFlight, maxInFlight int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/conversations.archive", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(mockSuccessResp))
+	})
+
+	testServ := httptest.NewServer(mux)
+	defer testServ.Close()
+
+	var progress []int
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+	channel := Channel{
+		UserClient:  client,
+		Concurrency: 3,
+		OnProgress: func(done, total int) {
+			if total != len(channelIDs) {
+				t.Errorf("expected progress total: %v, got: %v", len(channelIDs), total)
+			}
+			progress = append(progress, done)
+		},
+	}
+
+	result, err := channel.ArchiveChannels(channelIDs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Succeeded) != len(channelIDs) {
+		t.Fatalf("expected %v channels archived, got %v", len(channelIDs), len(result.Succeeded))
+	}
+
+	if maxInFlight > 3 || maxInFlight < 2 {
+		t.Fatalf("expected between 2 and 3 concurrent requests, got %v", maxInFlight)
+	}
+
+	if diff := pretty.Compare(progress, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+}
+
+func TestArchiveChannelsRateLimited(t *testing.T) {
+	testCases := []struct {
+		description      string
+		rateLimitedTimes int
+		wantResult       wantBulkResult
+		wantErr          string
+	}{
+		{
+			description:      "rate limited call is retried",
+			rateLimitedTimes: 2,
+			wantResult:       wantBulkResult{succeeded: []string{"C1H9RESGL"}},
+		},
+		{
+			description:      "call still rate limited after max retries is reported as failed",
+			rateLimitedTimes: maxRateLimitRetries + 1,
+			wantResult:       wantBulkResult{failed: []string{"C1H9RESGL"}},
+			wantErr:          "slack rate limit exceeded, retry after 0s",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var calls int
+			mux := http.NewServeMux()
+			mux.HandleFunc("/conversations.archive", func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= tc.rateLimitedTimes {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				_, _ = w.Write([]byte(mockSuccessResp))
+			})
+
+			testServ := httptest.NewServer(mux)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+			channel := Channel{
+				UserClient: client,
+			}
+
+			result, err := channel.ArchiveChannels([]string{"C1H9RESGL"})
+
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+			}
+
+			checkBulkResult(t, result, tc.wantResult)
+		})
+	}
+}
+
+// wantBulkResult mirrors BulkResult, listing only the IDs of failed items
+type wantBulkResult struct {
+	succeeded []string
+	skipped   []BulkSkipped
+	failed    []string
+}
+
+func checkBulkResult(t *testing.T, result *BulkResult, want wantBulkResult) {
+	t.Helper()
+
+	var failed []string
+	for _, f := range result.Failed {
+		failed = append(failed, f.ID)
+	}
+
+	got := wantBulkResult{
+		succeeded: result.Succeeded,
+		skipped:   result.Skipped,
+		failed:    failed,
+	}
+
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+}
+
+func mockUserIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("U%08d", i)
+	}
+	return ids
+}
@@ -0,0 +1,308 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alyosha/slack-utils/mrkdwn"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	ConfirmActionID = "confirm_action"
+	// ConfirmBlockID is the block ID of a confirmation prompt's buttons,
+	// which distinguishes its Cancel button from other uses of CancelActionID
+	ConfirmBlockID = "confirm_actions"
+
+	OpArchiveChannels = "archive_channels"
+	OpLeaveChannels   = "leave_channels"
+
+	// DefaultConfirmTTL is how long a confirmation prompt stays valid if a
+	// Confirmer's TTL is not set
+	DefaultConfirmTTL = 15 * time.Minute
+
+	// buttonValueMaxLen is the max character length of a button's value
+	buttonValueMaxLen = 2000
+	// confirmInProgressText replaces a prompt while its operation runs
+	confirmInProgressText = "Working on it..."
+)
+
+var (
+	ErrConfirmationInvalid   = errors.New("confirmation has an invalid signature")
+	ErrConfirmationExpired   = errors.New("confirmation has expired")
+	ErrConfirmationWrongUser = errors.New("confirmation belongs to another user")
+	ErrConfirmationNoSecret  = errors.New("confirmer requires a secret")
+)
+
+// PendingAction is an operation awaiting confirmation, carried in the value
+// of the Confirm button
+type PendingAction struct {
+	// Op names the operation, a key of the Confirmer's Ops
+	Op string `json:"op"`
+	// Args are the operation's parameters, e.g. channel IDs
+	Args []string `json:"args,omitempty"`
+	// UserID is the user who requested the operation, and the only one who
+	// may confirm or cancel it
+	UserID string `json:"user"`
+	// Expires is set by Prompt, as a UNIX timestamp
+	Expires int64 `json:"exp"`
+}
+
+// ConfirmFunc carries out a confirmed operation, returning the outcome to
+// replace the prompt with
+type ConfirmFunc func(action PendingAction) (outcome string, err error)
+
+// Confirmer asks users to confirm destructive operations before they run.
+// Prompt builds an "Are you sure?" message whose buttons carry the pending
+// operation, signed with Secret so that it can't be tampered with, and
+// HandleAction runs or cancels it when a button is clicked, replacing the
+// prompt with the outcome. Set Dialog to also have Slack show a confirmation
+// dialog when Confirm is clicked.
+type Confirmer struct {
+	Secret string
+	Ops    map[string]ConfirmFunc
+	TTL    time.Duration
+	Dialog bool
+	// OnDone is called once a confirmed operation has run in the background
+	// and the prompt has been replaced with its outcome, with the error from
+	// either step (e.g. to log failures)
+	OnDone func(action PendingAction, err error)
+}
+
+// Prompt returns a message asking the user to confirm the action, with text
+// describing what will happen, e.g. "Archive 12 channels?"
+func (c *Confirmer) Prompt(action PendingAction, text string) (Msg, error) {
+	if c.Secret == "" {
+		return Msg{}, ErrConfirmationNoSecret
+	}
+
+	if _, ok := c.Ops[action.Op]; !ok {
+		return Msg{}, fmt.Errorf("unknown operation: %s", action.Op)
+	}
+
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = DefaultConfirmTTL
+	}
+	action.Expires = time.Now().Add(ttl).Unix()
+
+	value, err := c.sign(action)
+	if err != nil {
+		return Msg{}, err
+	}
+	if len(value) > buttonValueMaxLen {
+		return Msg{}, fmt.Errorf("pending %s is too large for a button value (%d characters)", action.Op, len(value))
+	}
+
+	confirmBtn := NewButton(ConfirmActionID, value, "Confirm", slack.StyleDanger)
+	if c.Dialog {
+		confirmBtn.Confirm = slack.NewConfirmationBlockObject(
+			slack.NewTextBlockObject(slack.PlainTextType, "Are you sure?", false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, text, false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, "Confirm", false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		).WithStyle(slack.StyleDanger)
+	}
+	cancelBtn := NewButton(CancelActionID, value, "Cancel", slack.StyleDefault)
+
+	return Msg{
+		Body: text,
+		Blocks: []slack.Block{
+			NewTextBlock(text, nil),
+			slack.NewActionBlock(ConfirmBlockID, confirmBtn, cancelBtn),
+		},
+	}, nil
+}
+
+// HandleAction runs or cancels the pending operation when a prompt's Confirm
+// or Cancel button is clicked, replacing the prompt with the outcome via the
+// callback's response_url or UpdateMsg. A confirmed operation runs in the
+// background once the prompt has been replaced with an in progress notice, so
+// that HandleAction returns within Slack's 3 second window for acknowledging
+// the action; use OnDone to find out how it went. An expired prompt is
+// replaced with a notice. A button with an invalid signature, or clicked by
+// someone other than the requesting user, is left alone and an error
+// returned. It returns false, doing nothing, if the callback is not for a
+// prompt.
+func (c *Confirmer) HandleAction(client *slack.Client, callback *slack.InteractionCallback) (bool, error) {
+	for _, blockAction := range callback.ActionCallback.BlockActions {
+		if blockAction.BlockID != ConfirmBlockID {
+			continue
+		}
+
+		switch blockAction.ActionID {
+		case CancelActionID:
+			return true, c.cancel(client, callback, blockAction.Value)
+		case ConfirmActionID:
+			return true, c.confirm(client, callback, blockAction.Value)
+		}
+	}
+
+	return false, nil
+}
+
+func (c *Confirmer) cancel(client *slack.Client, callback *slack.InteractionCallback, value string) error {
+	if _, err := c.authorize(callback, value); err != nil {
+		return err
+	}
+
+	return replaceCallbackMsg(client, callback, outcomeMsg("Cancelled."))
+}
+
+func (c *Confirmer) confirm(client *slack.Client, callback *slack.InteractionCallback, value string) error {
+	action, err := c.authorize(callback, value)
+	if err != nil {
+		return err
+	}
+
+	if time.Now().Unix() > action.Expires {
+		if err := replaceCallbackMsg(client, callback, outcomeMsg("This request has expired, please try again.")); err != nil {
+			return err
+		}
+		return ErrConfirmationExpired
+	}
+
+	op, ok := c.Ops[action.Op]
+	if !ok {
+		return fmt.Errorf("unknown operation: %s", action.Op)
+	}
+
+	// Replacing the prompt first removes its buttons, so the operation can't
+	// be confirmed twice while it runs
+	if err := replaceCallbackMsg(client, callback, outcomeMsg(confirmInProgressText)); err != nil {
+		return err
+	}
+
+	go c.run(client, *callback, op, action)
+
+	return nil
+}
+
+// run carries out a confirmed operation and replaces the in progress notice
+// with its outcome
+func (c *Confirmer) run(client *slack.Client, callback slack.InteractionCallback, op ConfirmFunc, action PendingAction) {
+	outcome, err := op(action)
+	if err != nil && outcome == "" {
+		outcome = "Something went wrong: " + mrkdwn.Escape(err.Error())
+	}
+
+	if replaceErr := replaceCallbackMsg(client, &callback, outcomeMsg(outcome)); err == nil {
+		err = replaceErr
+	}
+
+	if c.OnDone != nil {
+		c.OnDone(action, err)
+	}
+}
+
+// authorize verifies a button's value and that the user clicking it is the
+// one who requested the action
+func (c *Confirmer) authorize(callback *slack.InteractionCallback, value string) (PendingAction, error) {
+	action, err := c.verify(value)
+	if err != nil {
+		return action, err
+	}
+
+	if action.UserID != callback.User.ID {
+		return action, ErrConfirmationWrongUser
+	}
+
+	return action, nil
+}
+
+// sign encodes the action as base64 JSON followed by its HMAC-SHA256
+func (c *Confirmer) sign(action PendingAction) (string, error) {
+	payload, err := json.Marshal(action)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.mac(encoded)), nil
+}
+
+func (c *Confirmer) verify(value string) (PendingAction, error) {
+	var action PendingAction
+
+	// Anyone could sign a value with an empty secret
+	if c.Secret == "" {
+		return action, ErrConfirmationNoSecret
+	}
+
+	i := strings.LastIndex(value, ".")
+	if i < 0 {
+		return action, ErrConfirmationInvalid
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(value[i+1:])
+	if err != nil || !hmac.Equal(sig, c.mac(value[:i])) {
+		return action, ErrConfirmationInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(value[:i])
+	if err != nil {
+		return action, ErrConfirmationInvalid
+	}
+	if err := json.Unmarshal(payload, &action); err != nil {
+		return action, ErrConfirmationInvalid
+	}
+
+	return action, nil
+}
+
+func (c *Confirmer) mac(data string) []byte {
+	h := hmac.New(sha256.New, []byte(c.Secret))
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// ArchiveChannelsOp returns a ConfirmFunc archiving the channels in the
+// action's Args, for use as the Confirmer's OpArchiveChannels
+func ArchiveChannelsOp(c *Channel) ConfirmFunc {
+	return func(action PendingAction) (string, error) {
+		result, err := c.ArchiveChannels(action.Args)
+		return bulkOutcome("Archived", result), err
+	}
+}
+
+// LeaveChannelsOp returns a ConfirmFunc leaving the channels in the action's
+// Args, for use as the Confirmer's OpLeaveChannels
+func LeaveChannelsOp(c *Channel) ConfirmFunc {
+	return func(action PendingAction) (string, error) {
+		result, err := c.LeaveChannels(action.Args)
+		return bulkOutcome("Left", result), err
+	}
+}
+
+// bulkOutcome summarizes a bulk channel operation, e.g. "Archived 3 channels,
+// skipped 1, failed 1."
+func bulkOutcome(verb string, result *BulkResult) string {
+	if result == nil {
+		return ""
+	}
+
+	noun := "channels"
+	if len(result.Succeeded) == 1 {
+		noun = "channel"
+	}
+	outcome := fmt.Sprintf("%s %d %s", verb, len(result.Succeeded), noun)
+
+	if len(result.Skipped) > 0 {
+		outcome += fmt.Sprintf(", skipped %d", len(result.Skipped))
+	}
+	if len(result.Failed) > 0 {
+		outcome += fmt.Sprintf(", failed %d", len(result.Failed))
+	}
+
+	return outcome + "."
+}
+
+func outcomeMsg(text string) Msg {
+	return Msg{Body: text, Blocks: []slack.Block{NewTextBlock(text, nil)}}
+}
@@ -0,0 +1,307 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+func TestConfirmerPrompt(t *testing.T) {
+	confirmer := &Confirmer{
+		Secret: "s3cr3t",
+		Ops:    map[string]ConfirmFunc{OpArchiveChannels: nil},
+		Dialog: true,
+	}
+
+	msg, err := confirmer.Prompt(PendingAction{Op: OpArchiveChannels, Args: []string{"C1H9RESGL"}, UserID: "U0G9QF9C6"}, "Archive 1 channel?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := "Archive 1 channel?\n\n[Confirm]  [Cancel]"; PreviewText(msg.Blocks) != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, PreviewText(msg.Blocks))
+	}
+
+	buttons := msg.Blocks[1].(*slack.ActionBlock).Elements.ElementSet
+	confirmBtn := buttons[0].(*slack.ButtonBlockElement)
+	if confirmBtn.Confirm == nil || confirmBtn.Confirm.Text.Text != "Archive 1 channel?" {
+		t.Fatalf("expected a confirmation dialog on the button, got: %+v", confirmBtn.Confirm)
+	}
+
+	action, err := confirmer.verify(confirmBtn.Value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action.Op != OpArchiveChannels || action.UserID != "U0G9QF9C6" || len(action.Args) != 1 || action.Expires <= time.Now().Unix() {
+		t.Fatalf("unexpected pending action: %+v", action)
+	}
+
+	if _, err := confirmer.Prompt(PendingAction{Op: "delete_everything"}, "Sure?"); err == nil || err.Error() != "unknown operation: delete_everything" {
+		t.Fatalf("expected unknown operation error, got: %v", err)
+	}
+
+	noSecret := &Confirmer{Ops: confirmer.Ops}
+	if _, err := noSecret.Prompt(PendingAction{Op: OpArchiveChannels}, "Sure?"); err != ErrConfirmationNoSecret {
+		t.Fatalf("expected missing secret error, got: %v", err)
+	}
+	if _, err := noSecret.verify(confirmBtn.Value); err != ErrConfirmationNoSecret {
+		t.Fatalf("expected missing secret error, got: %v", err)
+	}
+
+	tooMany := make([]string, 200)
+	for i := range tooMany {
+		tooMany[i] = "C1H9RESGL"
+	}
+	if _, err := confirmer.Prompt(PendingAction{Op: OpArchiveChannels, Args: tooMany}, "Sure?"); err == nil || !strings.Contains(err.Error(), "too large for a button value") {
+		t.Fatalf("expected value length error, got: %v", err)
+	}
+}
+
+func TestConfirmerHandleAction(t *testing.T) {
+	var ran []PendingAction
+	done := make(chan error, 1)
+	confirmer := &Confirmer{
+		Secret: "s3cr3t",
+		Ops: map[string]ConfirmFunc{
+			OpArchiveChannels: func(action PendingAction) (string, error) {
+				ran = append(ran, action)
+				return "Archived 1 channel.", nil
+			},
+			OpLeaveChannels: func(action PendingAction) (string, error) {
+				ran = append(ran, action)
+				return "", errors.New("not_in_channel <C1H9RESGL>")
+			},
+		},
+		OnDone: func(action PendingAction, err error) {
+			done <- err
+		},
+	}
+
+	promptValue := func(op string) string {
+		msg, err := confirmer.Prompt(PendingAction{Op: op, Args: []string{"C1H9RESGL"}, UserID: "U0G9QF9C6"}, "Sure?")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return msg.Blocks[1].(*slack.ActionBlock).Elements.ElementSet[0].(*slack.ButtonBlockElement).Value
+	}
+	value, failingValue := promptValue(OpArchiveChannels), promptValue(OpLeaveChannels)
+
+	expired, err := confirmer.sign(PendingAction{Op: OpArchiveChannels, UserID: "U0G9QF9C6", Expires: time.Now().Add(-time.Minute).Unix()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	forged, err := (&Confirmer{Secret: "guess"}).sign(PendingAction{Op: OpArchiveChannels, UserID: "U0G9QF9C6", Expires: time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		description string
+		blockID     string
+		actionID    string
+		value       string
+		userID      string
+		wantHandled bool
+		wantRan     bool
+		wantOutcome string
+		wantErr     string
+		wantDoneErr string
+	}{
+		{
+			description: "runs the confirmed operation",
+			blockID:     ConfirmBlockID,
+			actionID:    ConfirmActionID,
+			value:       value,
+			userID:      "U0G9QF9C6",
+			wantHandled: true,
+			wantRan:     true,
+			wantOutcome: "Archived 1 channel.",
+		},
+		{
+			description: "reports failed operations",
+			blockID:     ConfirmBlockID,
+			actionID:    ConfirmActionID,
+			value:       failingValue,
+			userID:      "U0G9QF9C6",
+			wantHandled: true,
+			wantRan:     true,
+			wantOutcome: "Something went wrong: not_in_channel &lt;C1H9RESGL&gt;",
+			wantDoneErr: "not_in_channel <C1H9RESGL>",
+		},
+		{
+			description: "cancels",
+			blockID:     ConfirmBlockID,
+			actionID:    CancelActionID,
+			value:       value,
+			userID:      "U0G9QF9C6",
+			wantHandled: true,
+			wantOutcome: "Cancelled.",
+		},
+		{
+			description: "rejects cancels by other users",
+			blockID:     ConfirmBlockID,
+			actionID:    CancelActionID,
+			value:       value,
+			userID:      "W07QCRPA4",
+			wantHandled: true,
+			wantErr:     ErrConfirmationWrongUser.Error(),
+		},
+		{
+			description: "rejects unsigned cancels",
+			blockID:     ConfirmBlockID,
+			actionID:    CancelActionID,
+			value:       "cancel",
+			userID:      "U0G9QF9C6",
+			wantHandled: true,
+			wantErr:     ErrConfirmationInvalid.Error(),
+		},
+		{
+			description: "replaces expired prompts",
+			blockID:     ConfirmBlockID,
+			actionID:    ConfirmActionID,
+			value:       expired,
+			userID:      "U0G9QF9C6",
+			wantHandled: true,
+			wantOutcome: "This request has expired, please try again.",
+			wantErr:     ErrConfirmationExpired.Error(),
+		},
+		{
+			description: "rejects forged values",
+			blockID:     ConfirmBlockID,
+			actionID:    ConfirmActionID,
+			value:       forged,
+			userID:      "U0G9QF9C6",
+			wantHandled: true,
+			wantErr:     ErrConfirmationInvalid.Error(),
+		},
+		{
+			description: "rejects other users",
+			blockID:     ConfirmBlockID,
+			actionID:    ConfirmActionID,
+			value:       value,
+			userID:      "W07QCRPA4",
+			wantHandled: true,
+			wantErr:     ErrConfirmationWrongUser.Error(),
+		},
+		{
+			description: "ignores cancel buttons outside prompts",
+			blockID:     "survey_actions",
+			actionID:    CancelActionID,
+			value:       "cancel",
+			userID:      "U0G9QF9C6",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ran = nil
+
+			var (
+				mu       sync.Mutex
+				outcomes []string
+			)
+			mux := http.NewServeMux()
+			mux.HandleFunc("/chat.update", func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				outcomes = append(outcomes, r.FormValue("text"))
+				mu.Unlock()
+				_, _ = w.Write([]byte(mockUpdateMsgResp))
+			})
+
+			testServ := httptest.NewServer(mux)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+			callback := &slack.InteractionCallback{
+				Type:      slack.InteractionTypeBlockActions,
+				User:      slack.User{ID: tc.userID},
+				Container: slack.Container{ChannelID: "C1H9RESGL", MessageTs: "1503435956.000247"},
+			}
+			callback.ActionCallback.BlockActions = []*slack.BlockAction{{BlockID: tc.blockID, ActionID: tc.actionID, Value: tc.value}}
+
+			handled, err := confirmer.HandleAction(client, callback)
+
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+			}
+
+			if handled != tc.wantHandled {
+				t.Fatalf("expected handled: %v, got: %v", tc.wantHandled, handled)
+			}
+
+			var wantOutcomes []string
+			if tc.wantRan {
+				// The operation runs in the background after the prompt is
+				// replaced with the in progress notice
+				select {
+				case doneErr := <-done:
+					if tc.wantDoneErr == "" && doneErr != nil {
+						t.Fatalf("unexpected error: %v", doneErr)
+					}
+					if tc.wantDoneErr != "" && (doneErr == nil || doneErr.Error() != tc.wantDoneErr) {
+						t.Fatalf("expected to receive error: %s, got: %v", tc.wantDoneErr, doneErr)
+					}
+				case <-time.After(time.Second):
+					t.Fatal("operation did not finish")
+				}
+				wantOutcomes = append(wantOutcomes, confirmInProgressText)
+			}
+			if tc.wantOutcome != "" {
+				wantOutcomes = append(wantOutcomes, tc.wantOutcome)
+			}
+
+			if ran := len(ran) > 0; ran != tc.wantRan {
+				t.Fatalf("expected operation to run: %v, got: %v", tc.wantRan, ran)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if strings.Join(outcomes, "\n") != strings.Join(wantOutcomes, "\n") {
+				t.Fatalf("expected outcomes: %q, got: %q", wantOutcomes, outcomes)
+			}
+		})
+	}
+}
+
+func TestArchiveChannelsOp(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/conversations.archive", func(w http.ResponseWriter, r *http.Request) {
+		resp := map[string]string{
+			"C1H9RESGL": mockChannelAlreadyArchivedErrResp,
+			"C0DEL09A5": mockSuccessResp,
+			"C0NEWCHAN": mockChannelsArchiveErrResp,
+		}
+		_, _ = w.Write([]byte(resp[r.FormValue("channel")]))
+	})
+
+	testServ := httptest.NewServer(mux)
+	defer testServ.Close()
+
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+	op := ArchiveChannelsOp(&Channel{UserClient: client, ContinueOnError: true})
+
+	outcome, err := op(PendingAction{Op: OpArchiveChannels, Args: []string{"C1H9RESGL", "C0DEL09A5", "C0NEWCHAN"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Archived 1 channel, skipped 1, failed 1."; outcome != want {
+		t.Fatalf("expected outcome: %q, got: %q", want, outcome)
+	}
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	DateRangeStartActionID = "date_range_start"
+	DateRangeEndActionID   = "date_range_end"
+)
+
+const (
+	// dateRangeStartSuffix and dateRangeEndSuffix are appended to the block ID
+	// of the range to form the block IDs of its two input blocks
+	dateRangeStartSuffix = "_start"
+	dateRangeEndSuffix   = "_end"
+)
+
+// DateRangeError is returned when the selected date range is incomplete or
+// invalid. Its message is written to be shown to the user as is.
+type DateRangeError struct {
+	Msg string
+}
+
+func (e *DateRangeError) Error() string {
+	return e.Msg
+}
+
+var (
+	ErrDateRangeStartMissing   = &DateRangeError{Msg: "Please choose a start date."}
+	ErrDateRangeEndMissing     = &DateRangeError{Msg: "Please choose an end date."}
+	ErrDateRangeEndBeforeStart = &DateRangeError{Msg: "The end date can't be before the start date."}
+)
+
+// DateRange is a start and end date selected by the user, both at midnight in
+// the user's timezone. End is inclusive and may equal Start.
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// NewDateRangeBlock returns an actions block holding a start and an end date
+// picker, for use in messages. The pickers are initialized to start and end in
+// loc, or show a placeholder where either is zero.
+func NewDateRangeBlock(blockID string, start, end time.Time, loc *time.Location) *slack.ActionBlock {
+	return slack.NewActionBlock(
+		blockID,
+		newDateRangePicker(DateRangeStartActionID, "Start date", start, loc),
+		newDateRangePicker(DateRangeEndActionID, "End date", end, loc),
+	)
+}
+
+// NewDateRangeInputBlocks returns a pair of input blocks holding a start and
+// an end date picker, for use in modals, where validation errors can only be
+// shown against input blocks. Their block IDs are formed by appending _start
+// and _end to blockID.
+func NewDateRangeInputBlocks(blockID, startLabel, endLabel string, start, end time.Time, loc *time.Location) []slack.Block {
+	return []slack.Block{
+		slack.NewInputBlock(
+			blockID+dateRangeStartSuffix,
+			slack.NewTextBlockObject(slack.PlainTextType, startLabel, false, false),
+			nil,
+			newDateRangePicker(DateRangeStartActionID, "Start date", start, loc),
+		),
+		slack.NewInputBlock(
+			blockID+dateRangeEndSuffix,
+			slack.NewTextBlockObject(slack.PlainTextType, endLabel, false, false),
+			nil,
+			newDateRangePicker(DateRangeEndActionID, "End date", end, loc),
+		),
+	}
+}
+
+// DateRangeFromCallback extracts the range selected in the blocks created with
+// blockID from a block_actions or view_submission callback, see
+// DateRangeFromState
+func DateRangeFromCallback(callback *slack.InteractionCallback, blockID string, loc *time.Location) (DateRange, error) {
+	var values map[string]map[string]slack.BlockAction
+	switch {
+	case callback.View.State != nil:
+		values = callback.View.State.Values
+	case callback.BlockActionState != nil:
+		values = callback.BlockActionState.Values
+	}
+
+	return DateRangeFromState(values, blockID, loc)
+}
+
+// DateRangeFromState extracts the range selected in the blocks created with
+// blockID (by either NewDateRangeBlock or NewDateRangeInputBlocks) from the
+// state values of a message or view. A *DateRangeError is returned if either
+// date is missing or the end is before the start.
+func DateRangeFromState(values map[string]map[string]slack.BlockAction, blockID string, loc *time.Location) (DateRange, error) {
+	startBlock, endBlock := values[blockID], values[blockID]
+	if startBlock == nil {
+		startBlock, endBlock = values[blockID+dateRangeStartSuffix], values[blockID+dateRangeEndSuffix]
+	}
+
+	startOpt, endOpt := startBlock[DateRangeStartActionID].SelectedDate, endBlock[DateRangeEndActionID].SelectedDate
+	if startOpt == "" {
+		return DateRange{}, ErrDateRangeStartMissing
+	}
+	if endOpt == "" {
+		return DateRange{}, ErrDateRangeEndMissing
+	}
+
+	start, err := DateOptToTimeIn(startOpt, loc)
+	if err != nil {
+		return DateRange{}, err
+	}
+	end, err := DateOptToTimeIn(endOpt, loc)
+	if err != nil {
+		return DateRange{}, err
+	}
+
+	if end.Before(start) {
+		return DateRange{}, ErrDateRangeEndBeforeStart
+	}
+
+	return DateRange{Start: start, End: end}, nil
+}
+
+// DateRangeErrorResponse returns a view submission response showing err
+// against the relevant input block created by NewDateRangeInputBlocks, or nil
+// if err is not a *DateRangeError
+func DateRangeErrorResponse(blockID string, err error) *slack.ViewSubmissionResponse {
+	rangeErr, ok := err.(*DateRangeError)
+	if !ok {
+		return nil
+	}
+
+	inputBlockID := blockID + dateRangeEndSuffix
+	if rangeErr == ErrDateRangeStartMissing {
+		inputBlockID = blockID + dateRangeStartSuffix
+	}
+
+	return slack.NewErrorsViewSubmissionResponse(map[string]string{inputBlockID: rangeErr.Msg})
+}
+
+func newDateRangePicker(actionID, placeholder string, initialDate time.Time, loc *time.Location) *slack.DatePickerBlockElement {
+	if initialDate.IsZero() {
+		return NewDatePickerWithOpts(actionID, slack.NewTextBlockObject(slack.PlainTextType, placeholder, false, false), initialDate)
+	}
+	return NewDatePickerInZone(actionID, nil, initialDate, loc)
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/slack-go/slack"
+)
+
+func TestNewDateRangeBlock(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("received unexpected error: %s", err)
+	}
+
+	start := time.Date(2020, time.March, 31, 20, 0, 0, 0, time.UTC)
+	block := NewDateRangeBlock("survey_dates", start, time.Time{}, tokyo)
+
+	wantBlock := &slack.ActionBlock{
+		Type:    slack.MBTAction,
+		BlockID: "survey_dates",
+		Elements: &slack.BlockElements{
+			ElementSet: []slack.BlockElement{
+				&slack.DatePickerBlockElement{
+					Type:        slack.METDatepicker,
+					ActionID:    DateRangeStartActionID,
+					InitialDate: "2020-04-01",
+				},
+				&slack.DatePickerBlockElement{
+					Type:        slack.METDatepicker,
+					ActionID:    DateRangeEndActionID,
+					Placeholder: slack.NewTextBlockObject(slack.PlainTextType, "End date", false, false),
+				},
+			},
+		},
+	}
+
+	if diff := pretty.Compare(block, wantBlock); diff != "" {
+		t.Fatalf("+got -want %s\n", diff)
+	}
+}
+
+func TestDateRangeFromState(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("received unexpected error: %s", err)
+	}
+
+	actionsState := func(start, end string) map[string]map[string]slack.BlockAction {
+		return map[string]map[string]slack.BlockAction{
+			"survey_dates": {
+				DateRangeStartActionID: {SelectedDate: start},
+				DateRangeEndActionID:   {SelectedDate: end},
+			},
+		}
+	}
+
+	testCases := []struct {
+		description string
+		values      map[string]map[string]slack.BlockAction
+		wantRange   DateRange
+		wantErr     error
+	}{
+		{
+			description: "range from actions block",
+			values:      actionsState("2020-04-01", "2020-04-07"),
+			wantRange: DateRange{
+				Start: time.Date(2020, time.April, 1, 0, 0, 0, 0, tokyo),
+				End:   time.Date(2020, time.April, 7, 0, 0, 0, 0, tokyo),
+			},
+		},
+		{
+			description: "range from input blocks, single day",
+			values: map[string]map[string]slack.BlockAction{
+				"survey_dates_start": {DateRangeStartActionID: {SelectedDate: "2020-04-01"}},
+				"survey_dates_end":   {DateRangeEndActionID: {SelectedDate: "2020-04-01"}},
+			},
+			wantRange: DateRange{
+				Start: time.Date(2020, time.April, 1, 0, 0, 0, 0, tokyo),
+				End:   time.Date(2020, time.April, 1, 0, 0, 0, 0, tokyo),
+			},
+		},
+		{
+			description: "start date missing",
+			values:      actionsState("", "2020-04-07"),
+			wantErr:     ErrDateRangeStartMissing,
+		},
+		{
+			description: "end date missing",
+			values:      actionsState("2020-04-01", ""),
+			wantErr:     ErrDateRangeEndMissing,
+		},
+		{
+			description: "blocks missing from state",
+			values:      map[string]map[string]slack.BlockAction{},
+			wantErr:     ErrDateRangeStartMissing,
+		},
+		{
+			description: "end before start",
+			values:      actionsState("2020-04-07", "2020-04-01"),
+			wantErr:     ErrDateRangeEndBeforeStart,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			dateRange, err := DateRangeFromState(tc.values, "survey_dates", tokyo)
+			if err != tc.wantErr {
+				t.Fatalf("expected to receive error: %v, got: %v", tc.wantErr, err)
+			}
+
+			if !dateRange.Start.Equal(tc.wantRange.Start) || !dateRange.End.Equal(tc.wantRange.End) {
+				t.Fatalf("expected range: %v, got: %v", tc.wantRange, dateRange)
+			}
+		})
+	}
+}
+
+func TestDateRangeFromCallback(t *testing.T) {
+	callback := &slack.InteractionCallback{
+		Type: slack.InteractionTypeViewSubmission,
+		View: slack.View{
+			State: &slack.ViewState{
+				Values: map[string]map[string]slack.BlockAction{
+					"survey_dates_start": {DateRangeStartActionID: {SelectedDate: "2020-04-07"}},
+					"survey_dates_end":   {DateRangeEndActionID: {SelectedDate: "2020-04-01"}},
+				},
+			},
+		},
+	}
+
+	_, err := DateRangeFromCallback(callback, "survey_dates", time.UTC)
+	if err != ErrDateRangeEndBeforeStart {
+		t.Fatalf("expected to receive error: %v, got: %v", ErrDateRangeEndBeforeStart, err)
+	}
+
+	resp := DateRangeErrorResponse("survey_dates", err)
+	wantResp := &slack.ViewSubmissionResponse{
+		ResponseAction: slack.RAErrors,
+		Errors:         map[string]string{"survey_dates_end": ErrDateRangeEndBeforeStart.Msg},
+	}
+	if diff := pretty.Compare(resp, wantResp); diff != "" {
+		t.Fatalf("+got -want %s\n", diff)
+	}
+
+	if resp := DateRangeErrorResponse("survey_dates", ErrDateRangeStartMissing); resp.Errors["survey_dates_start"] == "" {
+		t.Fatalf("expected missing start to be reported against the start block, got: %v", resp.Errors)
+	}
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+var exportCSVHeader = []string{"ts", "thread_ts", "time", "user_id", "user_name", "user_email", "subtype", "text"}
+
+// ExportedMsg is a single channel message as written by the history exporters
+type ExportedMsg struct {
+	TS        string    `json:"ts"`
+	ThreadTS  string    `json:"thread_ts,omitempty"`
+	Time      time.Time `json:"time"`
+	UserID    string    `json:"user_id,omitempty"`
+	UserName  string    `json:"user_name,omitempty"`
+	UserEmail string    `json:"user_email,omitempty"`
+	SubType   string    `json:"subtype,omitempty"`
+	Text      string    `json:"text"`
+}
+
+// ExportChannelJSON writes the full history of the channel, thread replies
+// included, to w as a JSON document of the form
+// {"channel_id": "C123", "messages": [...]}. Messages are written as they are
+// fetched, newest first, with the replies to a thread following its parent
+// message, so the history is never held in memory as a whole. Replies also
+// sent to the channel are written once, where they appear in the history.
+func ExportChannelJSON(client *slack.Client, channelID string, w io.Writer) error {
+	if _, err := fmt.Fprintf(w, `{"channel_id":%q,"messages":[`, channelID); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	first := true
+	err := exportHistory(client, channelID, func(msg ExportedMsg) error {
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		return enc.Encode(msg)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]}\n")
+	return err
+}
+
+// ExportChannelCSV writes the full history of the channel, thread replies
+// included, to w as CSV with one row per message, in the same order as
+// ExportChannelJSON
+func ExportChannelCSV(client *slack.Client, channelID string, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportCSVHeader); err != nil {
+		return err
+	}
+
+	err := exportHistory(client, channelID, func(msg ExportedMsg) error {
+		return cw.Write([]string{
+			msg.TS,
+			msg.ThreadTS,
+			msg.Time.UTC().Format(time.RFC3339),
+			msg.UserID,
+			msg.UserName,
+			msg.UserEmail,
+			msg.SubType,
+			msg.Text,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportHistory pages through the channel's history, calling fn for each
+// message followed by each of its thread replies
+func exportHistory(client *slack.Client, channelID string, fn func(msg ExportedMsg) error) error {
+	directory := NewUserDirectory(client, 0)
+	if err := directory.Refresh(); err != nil {
+		return errors.Wrapf(err, "failed to get users")
+	}
+
+	emit := func(msg slack.Message) error {
+		exported := ExportedMsg{
+			TS:       msg.Timestamp,
+			ThreadTS: msg.ThreadTimestamp,
+			Time:     tsToTime(msg.Timestamp),
+			UserID:   msg.User,
+			SubType:  msg.SubType,
+			Text:     msg.Text,
+		}
+		if user, err := directory.UserByID(msg.User); err == nil {
+			exported.UserName = user.Profile.RealName
+			if exported.UserName == "" {
+				exported.UserName = user.Name
+			}
+			exported.UserEmail = user.Profile.Email
+		}
+		return fn(exported)
+	}
+
+	params := &slack.GetConversationHistoryParameters{
+		ChannelID: channelID,
+		Limit:     historyPageSize,
+	}
+
+	for {
+		var resp *slack.GetConversationHistoryResponse
+		err := callWithRetry(context.Background(), func() (err error) {
+			resp, err = client.GetConversationHistory(params)
+			return err
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get channel history")
+		}
+
+		for _, msg := range resp.Messages {
+			if err := emit(msg); err != nil {
+				return err
+			}
+
+			if msg.ReplyCount == 0 {
+				continue
+			}
+
+			if err := exportReplies(client, channelID, msg.Timestamp, emit); err != nil {
+				return errors.Wrapf(err, "failed to get thread replies")
+			}
+		}
+
+		if !resp.HasMore || resp.ResponseMetaData.NextCursor == "" {
+			return nil
+		}
+		params.Cursor = resp.ResponseMetaData.NextCursor
+	}
+}
+
+// exportReplies pages through the replies of the thread started by threadTS,
+// calling emit for each reply but not the parent message itself. Replies
+// also sent to the channel are skipped, as the history includes them.
+func exportReplies(client *slack.Client, channelID, threadTS string, emit func(msg slack.Message) error) error {
+	params := &slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: threadTS,
+		Limit:     historyPageSize,
+	}
+
+	for {
+		var (
+			msgs   []slack.Message
+			cursor string
+		)
+		err := callWithRetry(context.Background(), func() (err error) {
+			msgs, _, cursor, err = client.GetConversationReplies(params)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, msg := range msgs {
+			if msg.Timestamp == threadTS || msg.SubType == "thread_broadcast" {
+				continue
+			}
+			if err := emit(msg); err != nil {
+				return err
+			}
+		}
+
+		if cursor == "" {
+			return nil
+		}
+		params.Cursor = cursor
+	}
+}
@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/slack-go/slack"
+)
+
+func newExportServer(respHistory, respUsersList []byte) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(respUsersList)
+	})
+	mux.HandleFunc("/conversations.history", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("cursor") != "" {
+			_, _ = w.Write([]byte(mockHistoryLastPageResp))
+			return
+		}
+		_, _ = w.Write(respHistory)
+	})
+	mux.HandleFunc("/conversations.replies", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("cursor") != "" {
+			_, _ = w.Write([]byte(mockRepliesLastPageResp))
+			return
+		}
+		_, _ = w.Write([]byte(mockRepliesFirstPageResp))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestExportChannelJSON(t *testing.T) {
+	testCases := []struct {
+		description   string
+		respHistory   []byte
+		respUsersList []byte
+		wantMsgs      []ExportedMsg
+		wantErr       string
+	}{
+		{
+			description:   "successful export including thread replies",
+			respHistory:   []byte(mockHistoryFirstPageResp),
+			respUsersList: []byte(mockUsersListResp),
+			wantMsgs: []ExportedMsg{
+				// A reply also sent to the channel is exported once, where
+				// it appears in the history
+				{
+					TS:        "1512104600.000100",
+					ThreadTS:  "1512104434.000490",
+					Time:      time.Unix(1512104600, 100000).UTC(),
+					UserID:    "W07QCRPA4",
+					UserName:  "Glinda Southgood",
+					UserEmail: "glenda@south.oz.coven",
+					SubType:   "thread_broadcast",
+					Text:      "Thanks!",
+				},
+				{
+					TS:        "1512104434.000490",
+					ThreadTS:  "1512104434.000490",
+					Time:      time.Unix(1512104434, 490000).UTC(),
+					UserID:    "U0G9QF9C6",
+					UserName:  "Egon Spengler",
+					UserEmail: "spengler@ghostbusters.example.com",
+					Text:      "Anyone seen the containment unit?",
+				},
+				{
+					TS:        "1512104500.000100",
+					ThreadTS:  "1512104434.000490",
+					Time:      time.Unix(1512104500, 100000).UTC(),
+					UserID:    "W07QCRPA4",
+					UserName:  "Glinda Southgood",
+					UserEmail: "glenda@south.oz.coven",
+					Text:      `It's in the basement, "as always"`,
+				},
+				{
+					TS:        "1512085950.000216",
+					Time:      time.Unix(1512085950, 216000).UTC(),
+					UserID:    "W07QCRPA4",
+					UserName:  "Glinda Southgood",
+					UserEmail: "glenda@south.oz.coven",
+					Text:      "Morning, all",
+				},
+				{
+					TS:      "1512085861.000543",
+					Time:    time.Unix(1512085861, 543000).UTC(),
+					UserID:  "U1QNSQB9U",
+					SubType: "channel_join",
+					Text:    "<@U1QNSQB9U> has joined the channel",
+				},
+			},
+		},
+		{
+			description:   "failure to retrieve history",
+			respHistory:   []byte(mockConversationMembersErrResp),
+			respUsersList: []byte(mockUsersListResp),
+			wantErr:       "failed to get channel history: channel_not_found",
+		},
+		{
+			description:   "failure to retrieve users list",
+			respHistory:   []byte(mockHistoryFirstPageResp),
+			respUsersList: []byte(mockUsersListErrResp),
+			wantErr:       "failed to get users: invalid_cursor",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			testServ := newExportServer(tc.respHistory, tc.respUsersList)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+			var buf bytes.Buffer
+			err := ExportChannelJSON(client, "C1H9RESGL", &buf)
+
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+				return
+			}
+
+			var archive struct {
+				ChannelID string        `json:"channel_id"`
+				Messages  []ExportedMsg `json:"messages"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &archive); err != nil {
+				t.Fatalf("failed to unmarshal export: %v\n%s", err, buf.String())
+			}
+
+			if archive.ChannelID != "C1H9RESGL" {
+				t.Fatalf("expected channel id: C1H9RESGL, got: %s", archive.ChannelID)
+			}
+
+			for i := range archive.Messages {
+				archive.Messages[i].Time = archive.Messages[i].Time.UTC()
+			}
+
+			if diff := pretty.Compare(archive.Messages, tc.wantMsgs); diff != "" {
+				t.Fatalf("-got +want %s\n", diff)
+			}
+		})
+	}
+}
+
+func TestExportChannelCSV(t *testing.T) {
+	testServ := newExportServer([]byte(mockHistoryFirstPageResp), []byte(mockUsersListResp))
+	defer testServ.Close()
+
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+	var buf bytes.Buffer
+	if err := ExportChannelCSV(client, "C1H9RESGL", &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantCSV := `ts,thread_ts,time,user_id,user_name,user_email,subtype,text
+1512104600.000100,1512104434.000490,2017-12-01T05:03:20Z,W07QCRPA4,Glinda Southgood,glenda@south.oz.coven,thread_broadcast,Thanks!
+1512104434.000490,1512104434.000490,2017-12-01T05:00:34Z,U0G9QF9C6,Egon Spengler,spengler@ghostbusters.example.com,,Anyone seen the containment unit?
+1512104500.000100,1512104434.000490,2017-12-01T05:01:40Z,W07QCRPA4,Glinda Southgood,glenda@south.oz.coven,,"It's in the basement, ""as always"""
+1512085950.000216,,2017-11-30T23:52:30Z,W07QCRPA4,Glinda Southgood,glenda@south.oz.coven,,"Morning, all"
+1512085861.000543,,2017-11-30T23:51:01Z,U1QNSQB9U,,,channel_join,<@U1QNSQB9U> has joined the channel
+`
+
+	if diff := pretty.Compare(buf.String(), wantCSV); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+}
@@ -1,14 +1,11 @@
 module github.com/alyosha/slack-utils
 
-go 1.14
+go 1.16
 
 require (
-	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/go-chi/chi v4.1.1+incompatible
-	github.com/gorilla/websocket v1.4.0 // indirect
 	github.com/kylelemons/godebug v1.1.0
 	github.com/pkg/errors v0.8.1
-	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/slack-go/slack v0.6.4
+	github.com/slack-go/slack v0.12.5
 	golang.org/x/sync v0.0.0-20190423024810-112230192c58
 )
@@ -75,6 +75,28 @@ func UpdateMsg(client *slack.Client, msg Msg, channelID, timestamp string) error
 	return err
 }
 
+// replaceCallbackMsg replaces the message an interaction callback came from,
+// via the callback's response_url if there is one (as there is for ephemeral
+// messages) and UpdateMsg otherwise
+func replaceCallbackMsg(client *slack.Client, callback *slack.InteractionCallback, msg Msg) error {
+	channelID := callback.Channel.ID
+	if channelID == "" {
+		channelID = callback.Container.ChannelID
+	}
+
+	if callback.ResponseURL != "" {
+		_, _, err := client.PostMessage(channelID, append(getCommonOpts(msg), slack.MsgOptionReplaceOriginal(callback.ResponseURL))...)
+		return err
+	}
+
+	timestamp := callback.Message.Timestamp
+	if timestamp == "" {
+		timestamp = callback.Container.MessageTs
+	}
+
+	return UpdateMsg(client, msg, channelID, timestamp)
+}
+
 // DeleteMsg deletes the provided message in the channel designated by channelID
 func DeleteMsg(client *slack.Client, channelID, timestamp, responseURL string) error {
 	_, _, _, err := client.UpdateMessage(
@@ -123,57 +123,180 @@ const mockUpdateMsgResp = `{
     }
 }`
 
-const mockChannelInfoResp = `{
+const mockConversationMembersResp = `{
     "ok": true,
-    "channel": {
-        "id": "C1H9RESGL",
-        "name": "busting",
-        "is_channel": true,
-        "created": 1466025154,
-        "creator": "U0G9QF9C6",
-        "is_archived": false,
-        "is_general": false,
-        "name_normalized": "busting",
-        "is_shared": false,
-        "is_org_shared": false,
-        "is_member": true,
-        "is_private": false,
-        "is_mpim": false,
-        "last_read": "1503435939.000101",
-        "latest": {
-            "text": "Containment unit is 98% full",
-            "username": "ecto1138",
-            "bot_id": "B19LU7CSY",
-            "attachments": [
-                {
-                    "text": "Don't get too attached",
-                    "id": 1,
-                    "fallback": "This is an attachment fallback"
-                }
-            ],
+    "members": [
+        "U0G9QF9C6",
+        "U1QNSQB9U"
+    ],
+    "response_metadata": {
+        "next_cursor": ""
+    }
+}`
+
+const mockConversationMembersFirstPageResp = `{
+    "ok": true,
+    "members": [
+        "U0G9QF9C6"
+    ],
+    "response_metadata": {
+        "next_cursor": "e3VzZXJfaWQ6IFcxMjM0NTY3fQ=="
+    }
+}`
+
+const mockConversationMembersLastPageResp = `{
+    "ok": true,
+    "members": [
+        "U1QNSQB9U"
+    ],
+    "response_metadata": {
+        "next_cursor": ""
+    }
+}`
+
+const mockConversationsListFirstPageResp = `{
+    "ok": true,
+    "channels": [
+        {
+            "id": "C012AB3CD",
+            "name": "general",
+            "is_channel": true,
+            "is_archived": false,
+            "is_private": false,
+            "created": 1449252889,
+            "creator": "U012A3CDE",
+            "num_members": 4
+        },
+        {
+            "id": "G0AKFJBEU",
+            "name": "incident-41",
+            "is_channel": false,
+            "is_group": true,
+            "is_archived": true,
+            "is_private": true,
+            "created": 1449252889,
+            "creator": "U012A3CDE",
+            "num_members": 2
+        }
+    ],
+    "response_metadata": {
+        "next_cursor": "dGVhbTpDMDYxRkE1UEI="
+    }
+}`
+
+const mockConversationsListLastPageResp = `{
+    "ok": true,
+    "channels": [
+        {
+            "id": "C061EG9T2",
+            "name": "incident-42",
+            "is_channel": true,
+            "is_archived": false,
+            "is_private": false,
+            "created": 1449252889,
+            "creator": "U061F7AUR",
+            "num_members": 3
+        }
+    ],
+    "response_metadata": {
+        "next_cursor": ""
+    }
+}`
+
+const mockConversationsListErrResp = `{
+    "ok": false,
+    "error": "invalid_types"
+}`
+
+const mockHistoryFirstPageResp = `{
+    "ok": true,
+    "messages": [
+        {
             "type": "message",
-            "subtype": "bot_message",
-            "ts": "1503435956.000247"
+            "subtype": "thread_broadcast",
+            "user": "W07QCRPA4",
+            "text": "Thanks!",
+            "ts": "1512104600.000100",
+            "thread_ts": "1512104434.000490"
         },
-        "unread_count": 1,
-        "unread_count_display": 1,
-        "members": [
-            "U0G9QF9C6",
-            "U1QNSQB9U"
-        ],
-        "topic": {
-            "value": "Spiritual containment strategies",
-            "creator": "U0G9QF9C6",
-            "last_set": 1503435128
+        {
+            "type": "message",
+            "user": "U0G9QF9C6",
+            "text": "Anyone seen the containment unit?",
+            "ts": "1512104434.000490",
+            "thread_ts": "1512104434.000490",
+            "reply_count": 2
         },
-        "purpose": {
-            "value": "Discuss busting ghosts",
-            "creator": "U0G9QF9C6",
-            "last_set": 1503435128
+        {
+            "type": "message",
+            "user": "W07QCRPA4",
+            "text": "Morning, all",
+            "ts": "1512085950.000216"
+        }
+    ],
+    "has_more": true,
+    "response_metadata": {
+        "next_cursor": "bmV4dF90czoxNTEyMDg1ODYxMDAwNTQz"
+    }
+}`
+
+const mockHistoryLastPageResp = `{
+    "ok": true,
+    "messages": [
+        {
+            "type": "message",
+            "subtype": "channel_join",
+            "user": "U1QNSQB9U",
+            "text": "<@U1QNSQB9U> has joined the channel",
+            "ts": "1512085861.000543"
+        }
+    ],
+    "has_more": false,
+    "response_metadata": {
+        "next_cursor": ""
+    }
+}`
+
+const mockRepliesFirstPageResp = `{
+    "ok": true,
+    "messages": [
+        {
+            "type": "message",
+            "user": "U0G9QF9C6",
+            "text": "Anyone seen the containment unit?",
+            "ts": "1512104434.000490",
+            "thread_ts": "1512104434.000490",
+            "reply_count": 2
         },
-        "previous_names": [
-            "dusting"
-        ]
+        {
+            "type": "message",
+            "user": "W07QCRPA4",
+            "text": "It's in the basement, \"as always\"",
+            "ts": "1512104500.000100",
+            "thread_ts": "1512104434.000490"
+        }
+    ],
+    "has_more": true,
+    "response_metadata": {
+        "next_cursor": "bmV4dF90czoxNTEyMTA0NTAwMDAwMTAw"
+    }
+}`
+
+const mockRepliesLastPageResp = `{
+    "ok": true,
+    "messages": [
+        {
+            "type": "message",
+            "subtype": "thread_broadcast",
+            "user": "W07QCRPA4",
+            "text": "Thanks!",
+            "ts": "1512104600.000100",
+            "thread_ts": "1512104434.000490"
+        }
+    ],
+    "has_more": false,
+    "response_metadata": {
+        "next_cursor": ""
     }
 }`
 
@@ -279,6 +402,11 @@ const mockChannelCreateErrResp = `{
     "detail": "Value passed for 'name' contained unallowed special characters."
 }`
 
+const mockNameTakenErrResp = `{
+    "ok": false,
+    "error": "name_taken"
+}`
+
 const mockInviteMembersErrResp = `{
     "ok": false,
     "error": "cant_invite"
@@ -289,12 +417,37 @@ const mockCantInviteSelfErrResp = `{
     "error": "cant_invite_self"
 }`
 
+const mockAlreadyInChannelErrResp = `{
+    "ok": false,
+    "error": "already_in_channel"
+}`
+
+const mockNotInChannelResp = `{
+    "ok": true,
+    "not_in_channel": true
+}`
+
+const mockKickErrResp = `{
+    "ok": false,
+    "error": "restricted_action"
+}`
+
+const mockPinErrResp = `{
+    "ok": false,
+    "error": "not_pinnable"
+}`
+
+const mockBookmarkErrResp = `{
+    "ok": false,
+    "error": "invalid_link"
+}`
+
 const mockPostMsgErrResp = `{
     "ok": false,
     "error": "too_many_attachments"
 }`
 
-const mockChannelInfoErrResp = `{
+const mockConversationMembersErrResp = `{
     "ok": false,
     "error": "channel_not_found"
 }`
@@ -318,3 +471,86 @@ const mockChannelAlreadyArchivedErrResp = `{
     "ok": false,
     "error": "already_archived"
 }`
+
+const mockChannelNotArchivedErrResp = `{
+    "ok": false,
+    "error": "not_archived"
+}`
+
+const mockUsersListInactiveResp = `{
+    "ok": true,
+    "members": [
+        {
+            "id": "U0G9QF9C6",
+            "name": "spengler",
+            "deleted": false,
+            "is_bot": false,
+            "profile": {"email": "spengler@ghostbusters.example.com"}
+        },
+        {
+            "id": "U0OLDSPEN",
+            "name": "spengler-old",
+            "deleted": true,
+            "profile": {"email": "spengler@ghostbusters.example.com"}
+        },
+        {
+            "id": "U0STANTZ1",
+            "name": "stantz",
+            "deleted": true,
+            "profile": {"email": "stantz@ghostbusters.example.com"}
+        },
+        {
+            "id": "U0SLIMER1",
+            "name": "slimer",
+            "is_bot": true,
+            "profile": {"email": "slimer@ghostbusters.example.com"}
+        }
+    ],
+    "response_metadata": {
+        "next_cursor": ""
+    }
+}`
+
+const mockUsersNotFoundErrResp = `{
+    "ok": false,
+    "error": "users_not_found"
+}`
+
+const mockUserGroupsListResp = `{
+    "ok": true,
+    "usergroups": [
+        {
+            "id": "S0614TZR7",
+            "team_id": "T060RNRCH",
+            "is_usergroup": true,
+            "name": "On-call",
+            "description": "Whoever is on call this week",
+            "handle": "oncall",
+            "date_delete": 0,
+            "user_count": 2
+        },
+        {
+            "id": "S0615G0KT",
+            "team_id": "T060RNRCH",
+            "is_usergroup": true,
+            "name": "Design",
+            "description": "",
+            "handle": "design",
+            "date_delete": 1446746793,
+            "user_count": 0
+        }
+    ]
+}`
+
+const mockUserGroupMembersResp = `{
+    "ok": true,
+    "users": [
+        "U0G9QF9C6",
+        "W07QCRPA4"
+    ]
+}`
+
+const mockUserGroupErrResp = `{
+    "ok": false,
+    "error": "no_such_subteam"
+}`
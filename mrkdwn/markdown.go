@@ -0,0 +1,354 @@
+package mrkdwn
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// SectionTextMaxLen is the max character length of a section block's text
+	SectionTextMaxLen = 3000
+	// HeaderTextMaxLen is the max character length of a header block's text
+	HeaderTextMaxLen = 150
+
+	ruleText = "──────────"
+)
+
+var (
+	fencePattern      = regexp.MustCompile("^\\s*(```+|~~~+)")
+	headingPattern    = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	rulePattern       = regexp.MustCompile(`^\s{0,3}(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	bulletPattern     = regexp.MustCompile(`^(\s*)[-*+]\s+(?:\[([ xX])\]\s+)?(.*)$`)
+	orderedPattern    = regexp.MustCompile(`^(\s*)(\d+)[.)]\s+(.*)$`)
+	quotePattern      = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	tableRowPattern   = regexp.MustCompile(`^\s*\|.*\|\s*$`)
+	tableSepPattern   = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?\s*$`)
+	codeSpanPattern   = regexp.MustCompile("`+[^`]+`+")
+	linkPattern       = regexp.MustCompile(`!?\[([^\]]*)\]\((\S+?)(?:\s+"[^"]*")?\)`)
+	autolinkPattern   = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	boldItalicPattern = regexp.MustCompile(`\*\*\*(\S(?:.*?\S)?)\*\*\*|___(\S(?:.*?\S)?)___`)
+	boldPattern       = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	italicPattern     = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	strikePattern     = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	placeholderRegex  = regexp.MustCompile("\x02(\\d+)\x03")
+
+	// markerStripper removes the control characters convertSpan uses as
+	// markers, so that input containing them can't be mistaken for one
+	markerStripper = strings.NewReplacer("\x01", "", "\x02", "", "\x03", "")
+)
+
+type nodeKind int
+
+const (
+	textNode nodeKind = iota
+	headingNode
+	ruleNode
+	codeNode
+)
+
+// node is a run of converted Markdown: a heading, a horizontal rule, a code
+// block, or any number of lines of other mrkdwn text
+type node struct {
+	kind nodeKind
+	text string
+	// raw holds a heading's original Markdown, for use in plain_text fields
+	raw string
+}
+
+// FromMarkdown converts CommonMark/GitHub-flavoured Markdown to mrkdwn.
+// Headings become bold lines, lists use bullets, tables become preformatted
+// blocks and inline formatting and links are translated. Text is escaped, so
+// the Markdown may come from an untrusted source.
+func FromMarkdown(md string) string {
+	var parts []string
+	for _, n := range parseMarkdown(md) {
+		switch n.kind {
+		case headingNode:
+			parts = append(parts, Bold(n.text))
+		case ruleNode:
+			parts = append(parts, ruleText)
+		case codeNode:
+			parts = append(parts, CodeBlock(n.text))
+		default:
+			parts = append(parts, n.text)
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// BlocksFromMarkdown converts Markdown to a Block Kit layout: headings become
+// header blocks, horizontal rules become dividers, and everything else is
+// converted as by FromMarkdown into section blocks, split at line boundaries
+// (and code blocks re-fenced) as needed to stay within SectionTextMaxLen
+func BlocksFromMarkdown(md string) []slack.Block {
+	var (
+		blocks  []slack.Block
+		pending []string
+	)
+
+	flush := func() {
+		text := strings.Trim(strings.Join(pending, "\n"), "\n")
+		pending = nil
+		for _, chunk := range splitText(text, SectionTextMaxLen) {
+			blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, chunk, false, false), nil, nil))
+		}
+	}
+
+	for _, n := range parseMarkdown(md) {
+		switch n.kind {
+		case headingNode:
+			flush()
+			blocks = append(blocks, slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, truncate(plainText(n.raw), HeaderTextMaxLen), false, false)))
+		case ruleNode:
+			flush()
+			blocks = append(blocks, slack.NewDividerBlock())
+		case codeNode:
+			// Code blocks are given sections of their own so that an
+			// oversized one can be split and each part fenced again
+			flush()
+			for _, chunk := range splitText(n.text, SectionTextMaxLen-len(CodeBlock(""))) {
+				blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, CodeBlock(chunk), false, false), nil, nil))
+			}
+		default:
+			pending = append(pending, n.text)
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+func parseMarkdown(md string) []node {
+	var nodes []node
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), m[1]); i++ {
+				code = append(code, Escape(lines[i]))
+			}
+			nodes = append(nodes, node{kind: codeNode, text: strings.Join(code, "\n")})
+			continue
+		}
+
+		if tableRowPattern.MatchString(line) && i+1 < len(lines) && tableSepPattern.MatchString(lines[i+1]) {
+			rows := [][]string{splitTableRow(line)}
+			for i += 2; i < len(lines) && tableRowPattern.MatchString(lines[i]); i++ {
+				rows = append(rows, splitTableRow(lines[i]))
+			}
+			i--
+			nodes = append(nodes, node{kind: codeNode, text: Escape(formatTable(rows))})
+			continue
+		}
+
+		// Headings without text are left as plain text, as Slack rejects
+		// header blocks with empty text
+		heading := headingPattern.FindStringSubmatch(line)
+
+		switch {
+		case heading != nil && strings.TrimSpace(plainText(heading[2])) != "":
+			raw := heading[2]
+			nodes = append(nodes, node{kind: headingNode, text: convertInline(raw), raw: raw})
+		case rulePattern.MatchString(line):
+			nodes = append(nodes, node{kind: ruleNode})
+		default:
+			nodes = append(nodes, node{kind: textNode, text: convertLine(line)})
+		}
+	}
+
+	return nodes
+}
+
+// convertLine converts a single line of list, quote or paragraph text
+func convertLine(line string) string {
+	if m := bulletPattern.FindStringSubmatch(line); m != nil {
+		marker := "•"
+		switch m[2] {
+		case " ":
+			marker = "☐"
+		case "x", "X":
+			marker = "☑"
+		}
+		return listIndent(m[1]) + marker + " " + convertInline(m[3])
+	}
+
+	if m := orderedPattern.FindStringSubmatch(line); m != nil {
+		return listIndent(m[1]) + m[2] + ". " + convertInline(m[3])
+	}
+
+	if m := quotePattern.FindStringSubmatch(line); m != nil {
+		return "> " + convertInline(m[1])
+	}
+
+	return convertInline(strings.TrimSpace(line))
+}
+
+// listIndent normalizes the indentation of a nested list item to four spaces
+// per level, treating each tab or two spaces of Markdown indentation as a level
+func listIndent(indent string) string {
+	levels := strings.Count(indent, "\t") + strings.Count(indent, " ")/2
+	return strings.Repeat("    ", levels)
+}
+
+// convertInline escapes the text and translates inline formatting, links and
+// code spans to mrkdwn
+func convertInline(text string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range codeSpanPattern.FindAllStringIndex(text, -1) {
+		out.WriteString(convertSpan(text[last:loc[0]]))
+		out.WriteString(Code(Escape(strings.Trim(text[loc[0]:loc[1]], "`"))))
+		last = loc[1]
+	}
+	out.WriteString(convertSpan(text[last:]))
+
+	return out.String()
+}
+
+// convertSpan converts text containing no code spans
+func convertSpan(text string) string {
+	text = markerStripper.Replace(text)
+
+	// Links are swapped out for placeholders while formatting is converted,
+	// so that URLs containing *, _ or ~ are left alone
+	var links []string
+	placeholder := func(link string) string {
+		links = append(links, link)
+		return fmt.Sprintf("\x02%d\x03", len(links)-1)
+	}
+
+	text = linkPattern.ReplaceAllStringFunc(text, func(s string) string {
+		m := linkPattern.FindStringSubmatch(s)
+		return placeholder(Link(m[2], m[1]))
+	})
+	text = autolinkPattern.ReplaceAllStringFunc(text, func(s string) string {
+		return placeholder(Link(autolinkPattern.FindStringSubmatch(s)[1], ""))
+	})
+
+	text = Escape(text)
+
+	// Bold is marked with \x01 until italics are converted, as both use *.
+	// Bold italics go first, so that their markers are nested correctly.
+	text = boldItalicPattern.ReplaceAllString(text, "\x01_$1${2}_\x01")
+	text = boldPattern.ReplaceAllString(text, "\x01$1$2\x01")
+	text = italicPattern.ReplaceAllString(text, "_${1}_")
+	text = strikePattern.ReplaceAllString(text, "~$1~")
+	text = strings.ReplaceAll(text, "\x01", "*")
+
+	return placeholderRegex.ReplaceAllStringFunc(text, func(s string) string {
+		i, _ := strconv.Atoi(placeholderRegex.FindStringSubmatch(s)[1])
+		return links[i]
+	})
+}
+
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+
+	cells := strings.Split(line, "|")
+	for i, cell := range cells {
+		cells[i] = strings.TrimSpace(cell)
+	}
+
+	return cells
+}
+
+// formatTable lays the rows out in aligned columns, with a rule under the
+// header row
+func formatTable(rows [][]string) string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(rows)+1)
+	for r, row := range rows {
+		cells := make([]string, len(widths))
+		for i := range widths {
+			var cell string
+			if i < len(row) {
+				cell = row[i]
+			}
+			cells[i] = cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, " | "), " "))
+
+		if r == 0 {
+			rules := make([]string, len(widths))
+			for i, w := range widths {
+				rules[i] = strings.Repeat("-", w)
+			}
+			lines = append(lines, strings.Join(rules, "-+-"))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// splitText splits text into chunks of at most maxLen characters, breaking at
+// line boundaries where possible
+func splitText(text string, maxLen int) []string {
+	if text == "" {
+		return nil
+	}
+
+	var (
+		chunks  []string
+		current []rune
+	)
+	for _, line := range strings.Split(text, "\n") {
+		runes := []rune(line)
+		if len(current) > 0 && len(current)+1+len(runes) > maxLen {
+			chunks = append(chunks, string(current))
+			current = nil
+		}
+		for len(runes) > maxLen {
+			chunks = append(chunks, string(runes[:maxLen]))
+			runes = runes[maxLen:]
+		}
+		if len(current) > 0 {
+			current = append(current, '\n')
+		}
+		current = append(current, runes...)
+	}
+	chunks = append(chunks, string(current))
+
+	return chunks
+}
+
+// plainText reduces inline Markdown to its text, for plain_text fields: links
+// are replaced by their labels and formatting markers are dropped
+func plainText(md string) string {
+	md = codeSpanPattern.ReplaceAllStringFunc(md, func(s string) string {
+		return strings.Trim(s, "`")
+	})
+	md = linkPattern.ReplaceAllString(md, "$1")
+	md = autolinkPattern.ReplaceAllString(md, "$1")
+	md = boldPattern.ReplaceAllString(md, "$1$2")
+	md = italicPattern.ReplaceAllString(md, "$1")
+	return strikePattern.ReplaceAllString(md, "$1")
+}
+
+func truncate(text string, maxLen int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	return string(runes[:maxLen-1]) + "…"
+}
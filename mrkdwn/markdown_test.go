@@ -0,0 +1,152 @@
+package mrkdwn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/slack-go/slack"
+)
+
+func TestFromMarkdown(t *testing.T) {
+	testCases := []struct {
+		description string
+		md          string
+		want        string
+	}{
+		{
+			description: "inline formatting",
+			md:          "**bold**, __also bold__, *italic*, _italic_ and ~~struck~~ text",
+			want:        "*bold*, *also bold*, _italic_, _italic_ and ~struck~ text",
+		},
+		{
+			description: "bold and italic mixed",
+			md:          "**Breaking:** *all* endpoints",
+			want:        "*Breaking:* _all_ endpoints",
+		},
+		{
+			description: "bold italics",
+			md:          "***both*** and ___both___",
+			want:        "*_both_* and *_both_*",
+		},
+		{
+			description: "links, images and autolinks",
+			md:          "See [the docs](https://example.com/a_b*c) or ![diagram](https://example.com/d.png) or <https://example.com>",
+			want:        "See <https://example.com/a_b*c|the docs> or <https://example.com/d.png|diagram> or <https://example.com>",
+		},
+		{
+			description: "special characters escaped",
+			md:          "Fixes `a < b` when x > 1 & y < 2",
+			want:        "Fixes `a &lt; b` when x &gt; 1 &amp; y &lt; 2",
+		},
+		{
+			description: "code spans left unformatted",
+			md:          "Run `go test -run **Foo**`",
+			want:        "Run `go test -run **Foo**`",
+		},
+		{
+			description: "marker characters in input dropped",
+			md:          "hello \x027\x03 world \x01bold\x01 [x](https://example.com)",
+			want:        "hello 7 world bold <https://example.com|x>",
+		},
+		{
+			description: "headings",
+			md:          "# Release v1.2.0\n## Fixes ##",
+			want:        "*Release v1.2.0*\n*Fixes*",
+		},
+		{
+			description: "headings without text left as text",
+			md:          "# \n###   \nafter",
+			want:        "#\n###\nafter",
+		},
+		{
+			description: "lists",
+			md:          "- one\n* two\n  + nested **item**\n1. first\n2) second\n- [ ] todo\n- [x] done",
+			want:        "• one\n• two\n    • nested *item*\n1. first\n2. second\n☐ todo\n☑ done",
+		},
+		{
+			description: "quotes and rules",
+			md:          "> quoted *text*\n\n---",
+			want:        "> quoted _text_\n\n" + ruleText,
+		},
+		{
+			description: "fenced code preserved",
+			md:          "```go\nif a < b && **c** {\n}\n```\nafter",
+			want:        "```\nif a &lt; b &amp;&amp; **c** {\n}\n```\nafter",
+		},
+		{
+			description: "tables",
+			md:          "| Name | Status |\n|------|:------:|\n| api | **up** |\n| db | down |",
+			want:        "```\nName | Status\n-----+-------\napi  | **up**\ndb   | down\n```",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := FromMarkdown(tc.md); got != tc.want {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBlocksFromMarkdown(t *testing.T) {
+	md := "# Release [v1.2.0](https://example.com) **now**\nSome *notes*\n\n- item\n***\n```\ncode\n```"
+
+	want := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Release v1.2.0 now", false, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "Some _notes_\n\n• item", false, false), nil, nil),
+		slack.NewDividerBlock(),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "```\ncode\n```", false, false), nil, nil),
+	}
+
+	if diff := pretty.Compare(BlocksFromMarkdown(md), want); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+}
+
+func TestBlocksFromMarkdownEmptyHeading(t *testing.T) {
+	want := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "#\nafter", false, false), nil, nil),
+	}
+
+	if diff := pretty.Compare(BlocksFromMarkdown("# \nafter"), want); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+}
+
+func TestBlocksFromMarkdownLimits(t *testing.T) {
+	line := strings.Repeat("a", 1000)
+	heading := "# " + strings.Repeat("h", 200)
+	code := "```\n" + strings.Repeat(line+"\n", 4) + "```"
+	md := heading + "\n" + strings.Repeat(line+"\n", 4) + code
+
+	blocks := BlocksFromMarkdown(md)
+
+	header, ok := blocks[0].(*slack.HeaderBlock)
+	if !ok || len([]rune(header.Text.Text)) != HeaderTextMaxLen {
+		t.Fatalf("expected header truncated to %d characters, got: %v", HeaderTextMaxLen, blocks[0])
+	}
+
+	var sections []string
+	for _, block := range blocks[1:] {
+		section, ok := block.(*slack.SectionBlock)
+		if !ok {
+			t.Fatalf("expected section block, got: %v", block)
+		}
+		if len(section.Text.Text) > SectionTextMaxLen {
+			t.Fatalf("expected section text within %d characters, got %d", SectionTextMaxLen, len(section.Text.Text))
+		}
+		sections = append(sections, section.Text.Text)
+	}
+
+	if len(sections) != 4 {
+		t.Fatalf("expected 4 sections, got %d", len(sections))
+	}
+
+	for _, section := range sections[2:] {
+		if !strings.HasPrefix(section, "```\n") || !strings.HasSuffix(section, "\n```") {
+			t.Fatalf("expected split code block to be fenced, got: %.20q...", section)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Package mrkdwn builds text in Slack's mrkdwn format. Text from users or
+// other outside sources should be passed through Escape before being combined
+// with the other helpers, none of which escape their input.
+package mrkdwn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Special mentions notifying a whole channel or workspace
+const (
+	Here     = "<!here>"
+	Channel  = "<!channel>"
+	Everyone = "<!everyone>"
+)
+
+// Tokens for use in the format passed to Date, which Slack replaces with the
+// date or time in the reader's timezone and locale
+const (
+	FmtDateNum    = "{date_num}"
+	FmtDate       = "{date}"
+	FmtDateShort  = "{date_short}"
+	FmtDateLong   = "{date_long}"
+	FmtDatePretty = "{date_pretty}"
+	FmtTime       = "{time}"
+	FmtTimeSecs   = "{time_secs}"
+)
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Escape replaces the characters mrkdwn reserves for mentions and links (&, <
+// and >) with their HTML entities, so text can be shown exactly as given
+func Escape(text string) string {
+	return escaper.Replace(text)
+}
+
+// UserMention mentions the user with the given ID, e.g. <@U123ABC>
+func UserMention(userID string) string {
+	return "<@" + userID + ">"
+}
+
+// ChannelMention links to the channel with the given ID, e.g. <#C123ABC>
+func ChannelMention(channelID string) string {
+	return "<#" + channelID + ">"
+}
+
+// UserGroupMention mentions the user group with the given ID, e.g.
+// <!subteam^S123ABC>
+func UserGroupMention(userGroupID string) string {
+	return "<!subteam^" + userGroupID + ">"
+}
+
+// Link links to the URL, shown as label unless label is empty. The label is
+// escaped.
+func Link(url, label string) string {
+	if label == "" {
+		return "<" + url + ">"
+	}
+	return "<" + url + "|" + Escape(label) + ">"
+}
+
+// Date shows t in the reader's own timezone, laid out according to format
+// (e.g. mrkdwn.FmtDateLong + " at " + mrkdwn.FmtTime). Clients which cannot
+// render dates show fallback instead, or t in UTC if fallback is empty.
+func Date(t time.Time, format, fallback string) string {
+	if fallback == "" {
+		fallback = t.UTC().Format(time.RFC1123)
+	}
+	return fmt.Sprintf("<!date^%d^%s|%s>", t.Unix(), format, Escape(fallback))
+}
+
+// Bold returns *text*
+func Bold(text string) string {
+	return "*" + text + "*"
+}
+
+// Italic returns _text_
+func Italic(text string) string {
+	return "_" + text + "_"
+}
+
+// Strike returns ~text~
+func Strike(text string) string {
+	return "~" + text + "~"
+}
+
+// Code returns `text`
+func Code(text string) string {
+	return "`" + text + "`"
+}
+
+// CodeBlock returns text as a preformatted block
+func CodeBlock(text string) string {
+	return "```\n" + text + "\n```"
+}
+
+// Quote prefixes every line of text with >, quoting it as a whole
+func Quote(text string) string {
+	return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+}
+
+// BulletList returns the items as a bulleted list, one per line
+func BulletList(items ...string) string {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, "• "+item)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NumberedList returns the items as a numbered list, one per line
+func NumberedList(items ...string) string {
+	lines := make([]string, 0, len(items))
+	for i, item := range items {
+		lines = append(lines, strconv.Itoa(i+1)+". "+item)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,51 @@
+package mrkdwn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatting(t *testing.T) {
+	deadline := time.Date(2020, time.April, 1, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		description string
+		got         string
+		want        string
+	}{
+		{description: "escape", got: Escape("<b>Tom & Jerry</b>"), want: "&lt;b&gt;Tom &amp; Jerry&lt;/b&gt;"},
+		{description: "escape leaves entities text intact", got: Escape("&amp;"), want: "&amp;amp;"},
+		{description: "user mention", got: UserMention("U0G9QF9C6"), want: "<@U0G9QF9C6>"},
+		{description: "channel mention", got: ChannelMention("C1H9RESGL"), want: "<#C1H9RESGL>"},
+		{description: "user group mention", got: UserGroupMention("S0614TZR7"), want: "<!subteam^S0614TZR7>"},
+		{description: "link with label", got: Link("https://example.com/?a=1", "Q&A <draft>"), want: "<https://example.com/?a=1|Q&amp;A &lt;draft&gt;>"},
+		{description: "link without label", got: Link("https://example.com", ""), want: "<https://example.com>"},
+		{
+			description: "date with fallback",
+			got:         Date(deadline, FmtDateLong+" at "+FmtTime, "April 1st"),
+			want:        "<!date^1585733400^{date_long} at {time}|April 1st>",
+		},
+		{
+			description: "date without fallback",
+			got:         Date(deadline, FmtDateShort, ""),
+			want:        "<!date^1585733400^{date_short}|Wed, 01 Apr 2020 09:30:00 UTC>",
+		},
+		{description: "bold", got: Bold("important"), want: "*important*"},
+		{description: "italic", got: Italic("aside"), want: "_aside_"},
+		{description: "strike", got: Strike("wrong"), want: "~wrong~"},
+		{description: "code", got: Code("go test"), want: "`go test`"},
+		{description: "code block", got: CodeBlock("a := 1\nb := 2"), want: "```\na := 1\nb := 2\n```"},
+		{description: "quote", got: Quote("first\nsecond"), want: "> first\n> second"},
+		{description: "bullet list", got: BulletList("one", Bold("two")), want: "• one\n• *two*"},
+		{description: "numbered list", got: NumberedList("one", "two"), want: "1. one\n2. two"},
+		{description: "empty list", got: BulletList(), want: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if tc.got != tc.want {
+				t.Fatalf("expected: %q, got: %q", tc.want, tc.got)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package mrkdwn
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TokenType identifies the kind of segment a Token holds
+type TokenType int
+
+const (
+	// TextToken is plain text, unescaped
+	TextToken TokenType = iota
+	// UserToken is a user mention, <@U123ABC>
+	UserToken
+	// ChannelToken is a channel link, <#C123ABC|general>
+	ChannelToken
+	// UserGroupToken is a user group mention, <!subteam^S123ABC|@oncall>
+	UserGroupToken
+	// SpecialToken is a special mention such as <!here>, with ID set to its
+	// name (here, channel or everyone)
+	SpecialToken
+	// DateToken is a formatted date, <!date^1585733400^{date}|April 1st>, with
+	// ID set to the UNIX timestamp and Label to the fallback text
+	DateToken
+	// LinkToken is a URL, <https://example.com|label>, with ID set to the URL
+	LinkToken
+)
+
+// Token is a segment of Slack-formatted text
+type Token struct {
+	Type TokenType
+	// Text is the unescaped text of a TextToken
+	Text string
+	// ID is the ID of the mentioned user, channel or user group, the name of
+	// a special mention, the timestamp of a date or the URL of a link
+	ID string
+	// Label is the text following the | in a mention or link, if any
+	Label string
+	// Raw is the token as it appeared in the original text
+	Raw string
+}
+
+// UserNamer looks up the name to show for a user, e.g. a UserDirectory
+type UserNamer interface {
+	DisplayName(userID string) (string, error)
+}
+
+var entityPattern = regexp.MustCompile(`<([^<>]*)>`)
+
+var unescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+// Parse splits Slack-formatted text, as received in message events or slash
+// command text, into plain text and the mentions, dates and links within it
+func Parse(text string) []Token {
+	var tokens []Token
+	last := 0
+	for _, loc := range entityPattern.FindAllStringSubmatchIndex(text, -1) {
+		if loc[0] > last {
+			tokens = append(tokens, newTextToken(text[last:loc[0]]))
+		}
+		tokens = append(tokens, parseEntity(text[loc[2]:loc[3]], text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	if last < len(text) {
+		tokens = append(tokens, newTextToken(text[last:]))
+	}
+
+	return tokens
+}
+
+// MentionedUsers returns the IDs of the users mentioned in the tokens, in the
+// order first mentioned
+func MentionedUsers(tokens []Token) []string {
+	var ids []string
+	seen := make(map[string]struct{})
+	for _, token := range tokens {
+		if token.Type != UserToken {
+			continue
+		}
+		if _, ok := seen[token.ID]; ok {
+			continue
+		}
+		seen[token.ID] = struct{}{}
+		ids = append(ids, token.ID)
+	}
+
+	return ids
+}
+
+// PlainText renders the tokens as plain text, as a reader would see them.
+// User mentions are shown as @name using namer if set, falling back to the
+// mention's label and then the user ID.
+func PlainText(tokens []Token, namer UserNamer) string {
+	var out strings.Builder
+	for _, token := range tokens {
+		switch token.Type {
+		case TextToken:
+			out.WriteString(token.Text)
+		case UserToken:
+			name := token.Label
+			if namer != nil {
+				if displayName, err := namer.DisplayName(token.ID); err == nil && displayName != "" {
+					name = displayName
+				}
+			}
+			if name == "" {
+				name = token.ID
+			}
+			out.WriteString("@" + strings.TrimPrefix(name, "@"))
+		case ChannelToken:
+			out.WriteString("#" + firstNonEmpty(token.Label, token.ID))
+		case UserGroupToken:
+			out.WriteString("@" + strings.TrimPrefix(firstNonEmpty(token.Label, token.ID), "@"))
+		case SpecialToken:
+			out.WriteString("@" + firstNonEmpty(strings.TrimPrefix(token.Label, "@"), token.ID))
+		case DateToken:
+			out.WriteString(token.Label)
+		case LinkToken:
+			out.WriteString(firstNonEmpty(token.Label, strings.TrimPrefix(token.ID, "mailto:")))
+		}
+	}
+
+	return out.String()
+}
+
+func newTextToken(text string) Token {
+	return Token{Type: TextToken, Text: unescaper.Replace(text), Raw: text}
+}
+
+// parseEntity parses the content of a <...> sequence
+func parseEntity(content, raw string) Token {
+	target, label := content, ""
+	if i := strings.Index(content, "|"); i >= 0 {
+		target, label = content[:i], unescaper.Replace(content[i+1:])
+	}
+
+	token := Token{Label: label, Raw: raw}
+	switch {
+	case strings.HasPrefix(target, "@"):
+		token.Type, token.ID = UserToken, target[1:]
+	case strings.HasPrefix(target, "#"):
+		token.Type, token.ID = ChannelToken, target[1:]
+	case strings.HasPrefix(target, "!subteam^"):
+		token.Type, token.ID = UserGroupToken, strings.TrimPrefix(target, "!subteam^")
+	case strings.HasPrefix(target, "!date^"):
+		token.Type = DateToken
+		token.ID = strings.SplitN(strings.TrimPrefix(target, "!date^"), "^", 2)[0]
+	case strings.HasPrefix(target, "!"):
+		token.Type, token.ID = SpecialToken, target[1:]
+	default:
+		token.Type, token.ID = LinkToken, unescaper.Replace(target)
+	}
+
+	return token
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package mrkdwn
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+type mockNamer map[string]string
+
+func (n mockNamer) DisplayName(userID string) (string, error) {
+	name, ok := n[userID]
+	if !ok {
+		return "", errors.New("user not found")
+	}
+	return name, nil
+}
+
+func TestParse(t *testing.T) {
+	text := "Hey <@U0G9QF9C6> and <@W07QCRPA4|glinda>, see <#C1H9RESGL|incidents> &amp; ping <!subteam^S0614TZR7|@oncall> <!here>: " +
+		"<https://example.com/?a=1&amp;b=2|Q&amp;A> by <!date^1585733400^{date_short}|Apr 1> or <mailto:help@example.com>"
+
+	want := []Token{
+		{Type: TextToken, Text: "Hey ", Raw: "Hey "},
+		{Type: UserToken, ID: "U0G9QF9C6", Raw: "<@U0G9QF9C6>"},
+		{Type: TextToken, Text: " and ", Raw: " and "},
+		{Type: UserToken, ID: "W07QCRPA4", Label: "glinda", Raw: "<@W07QCRPA4|glinda>"},
+		{Type: TextToken, Text: ", see ", Raw: ", see "},
+		{Type: ChannelToken, ID: "C1H9RESGL", Label: "incidents", Raw: "<#C1H9RESGL|incidents>"},
+		{Type: TextToken, Text: " & ping ", Raw: " &amp; ping "},
+		{Type: UserGroupToken, ID: "S0614TZR7", Label: "@oncall", Raw: "<!subteam^S0614TZR7|@oncall>"},
+		{Type: TextToken, Text: " ", Raw: " "},
+		{Type: SpecialToken, ID: "here", Raw: "<!here>"},
+		{Type: TextToken, Text: ": ", Raw: ": "},
+		{Type: LinkToken, ID: "https://example.com/?a=1&b=2", Label: "Q&A", Raw: "<https://example.com/?a=1&amp;b=2|Q&amp;A>"},
+		{Type: TextToken, Text: " by ", Raw: " by "},
+		{Type: DateToken, ID: "1585733400", Label: "Apr 1", Raw: "<!date^1585733400^{date_short}|Apr 1>"},
+		{Type: TextToken, Text: " or ", Raw: " or "},
+		{Type: LinkToken, ID: "mailto:help@example.com", Raw: "<mailto:help@example.com>"},
+	}
+
+	tokens := Parse(text)
+	if diff := pretty.Compare(tokens, want); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+
+	if diff := pretty.Compare(MentionedUsers(append(tokens, tokens...)), []string{"U0G9QF9C6", "W07QCRPA4"}); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+
+	wantPlain := "Hey @spengler and @glinda, see #incidents & ping @oncall @here: Q&A by Apr 1 or help@example.com"
+	if got := PlainText(tokens, mockNamer{"U0G9QF9C6": "spengler"}); got != wantPlain {
+		t.Fatalf("expected: %q, got: %q", wantPlain, got)
+	}
+
+	wantUnresolved := "Hey @U0G9QF9C6 and @glinda, see #incidents & ping @oncall @here: Q&A by Apr 1 or help@example.com"
+	if got := PlainText(tokens, nil); got != wantUnresolved {
+		t.Fatalf("expected: %q, got: %q", wantUnresolved, got)
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	text := Bold(Escape("a < b")) + " " + UserMention("U0G9QF9C6") + " " + Link("https://example.com", "docs")
+
+	var raw string
+	for _, token := range Parse(text) {
+		raw += token.Raw
+	}
+
+	if raw != text {
+		t.Fatalf("expected raw tokens to reassemble: %q, got: %q", text, raw)
+	}
+}
@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/alyosha/slack-utils/mrkdwn"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+// MsgMaxBlocks is the max number of blocks Slack accepts in a single message
+const MsgMaxBlocks = 50
+
+// TemplateFuncs are available in every MsgTemplates template, in addition to
+// any Funcs provided. The string functions return text ready to be placed
+// between the quotes of a JSON string:
+//
+//	escape:    mrkdwn-escapes user input, e.g. "text": "*{{ escape .Title }}*"
+//	str:       JSON-escapes text for plain_text fields without mrkdwn-escaping it
+//	json:      marshals any value to JSON, quotes included
+//	user, channel, usergroup: mention by ID
+//	link:      links a URL with an escaped label
+var TemplateFuncs = template.FuncMap{
+	"escape":    func(s string) string { return jsonString(mrkdwn.Escape(s)) },
+	"str":       jsonString,
+	"json":      jsonValue,
+	"user":      mrkdwn.UserMention,
+	"channel":   mrkdwn.ChannelMention,
+	"usergroup": mrkdwn.UserGroupMention,
+	"link":      func(url, label string) string { return jsonString(mrkdwn.Link(url, label)) },
+}
+
+// MsgTemplates renders messages from Block Kit JSON templates written with
+// text/template, such as layouts exported from Block Kit Builder with
+// placeholders added. A template may hold a bare array of blocks or an object
+// with "blocks" and optionally "text", which becomes the message body.
+//
+// Set DevMode to re-read the templates from FS on every render, so that edits
+// show up without restarting (e.g. with FS set to os.DirFS("templates")).
+type MsgTemplates struct {
+	FS       fs.FS
+	Patterns []string
+	Funcs    template.FuncMap
+	DevMode  bool
+
+	mu   sync.Mutex
+	tmpl *template.Template
+}
+
+// NewMsgTemplates parses the templates in fsys matching the patterns (see
+// fs.Glob), each of which is then rendered by its base name
+func NewMsgTemplates(fsys fs.FS, patterns ...string) (*MsgTemplates, error) {
+	t := &MsgTemplates{
+		FS:       fsys,
+		Patterns: patterns,
+	}
+
+	if err := t.Reload(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Reload parses the templates again from FS
+func (t *MsgTemplates) Reload() error {
+	tmpl := template.New("").Funcs(TemplateFuncs).Funcs(t.Funcs)
+	tmpl, err := tmpl.ParseFS(t.FS, t.Patterns...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse templates")
+	}
+
+	t.mu.Lock()
+	t.tmpl = tmpl
+	t.mu.Unlock()
+
+	return nil
+}
+
+// Render executes the named template with data and returns the resulting
+// message, having checked the blocks are ones Slack will accept
+func (t *MsgTemplates) Render(name string, data interface{}) (Msg, error) {
+	if t.DevMode {
+		if err := t.Reload(); err != nil {
+			return Msg{}, err
+		}
+	}
+
+	t.mu.Lock()
+	tmpl := t.tmpl
+	t.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return Msg{}, errors.Wrapf(err, "failed to render template")
+	}
+
+	msg, err := unmarshalMsgJSON(buf.Bytes())
+	if err != nil {
+		return Msg{}, errors.Wrapf(err, "template %s produced invalid JSON", name)
+	}
+
+	if err := ValidateBlocks(msg.Blocks); err != nil {
+		return Msg{}, errors.Wrapf(err, "template %s produced invalid blocks", name)
+	}
+
+	return msg, nil
+}
+
+// ValidateBlocks checks the blocks against the limits Slack enforces on
+// messages: the number of blocks, known block types, unique block IDs and the
+// length of section and header text
+func ValidateBlocks(blocks []slack.Block) error {
+	if len(blocks) > MsgMaxBlocks {
+		return fmt.Errorf("%d blocks exceeds the limit of %d", len(blocks), MsgMaxBlocks)
+	}
+
+	blockIDs := make(map[string]struct{}, len(blocks))
+	for i, block := range blocks {
+		if unknown, ok := block.(*slack.UnknownBlock); ok {
+			return fmt.Errorf("block %d has unknown type %q", i, unknown.Type)
+		}
+
+		if id := blockID(block); id != "" {
+			if _, ok := blockIDs[id]; ok {
+				return fmt.Errorf("block %d has duplicate block_id %q", i, id)
+			}
+			blockIDs[id] = struct{}{}
+		}
+
+		switch b := block.(type) {
+		case *slack.SectionBlock:
+			if b.Text == nil && len(b.Fields) == 0 {
+				return fmt.Errorf("section block %d has neither text nor fields", i)
+			}
+			if b.Text != nil && utf8.RuneCountInString(b.Text.Text) > mrkdwn.SectionTextMaxLen {
+				return fmt.Errorf("section block %d text exceeds %d characters", i, mrkdwn.SectionTextMaxLen)
+			}
+		case *slack.HeaderBlock:
+			if b.Text == nil || b.Text.Type != slack.PlainTextType {
+				return fmt.Errorf("header block %d requires plain_text text", i)
+			}
+			if utf8.RuneCountInString(b.Text.Text) > mrkdwn.HeaderTextMaxLen {
+				return fmt.Errorf("header block %d text exceeds %d characters", i, mrkdwn.HeaderTextMaxLen)
+			}
+		}
+	}
+
+	return nil
+}
+
+// unmarshalMsgJSON reads a message from either a bare array of blocks or an
+// object with blocks and text
+func unmarshalMsgJSON(data []byte) (Msg, error) {
+	var blocks slack.Blocks
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &blocks); err != nil {
+			return Msg{}, err
+		}
+		return Msg{Blocks: blocks.BlockSet}, nil
+	}
+
+	var payload struct {
+		Text   string       `json:"text"`
+		Blocks slack.Blocks `json:"blocks"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return Msg{}, err
+	}
+
+	return Msg{Body: payload.Text, Blocks: payload.Blocks.BlockSet}, nil
+}
+
+// blockID returns the block_id of the block, for the block types which have one
+func blockID(block slack.Block) string {
+	switch b := block.(type) {
+	case *slack.ActionBlock:
+		return b.BlockID
+	case *slack.ContextBlock:
+		return b.BlockID
+	case *slack.DividerBlock:
+		return b.BlockID
+	case *slack.FileBlock:
+		return b.BlockID
+	case *slack.HeaderBlock:
+		return b.BlockID
+	case *slack.ImageBlock:
+		return b.BlockID
+	case *slack.InputBlock:
+		return b.BlockID
+	case *slack.RichTextBlock:
+		return b.BlockID
+	case *slack.SectionBlock:
+		return b.BlockID
+	case *slack.VideoBlock:
+		return b.BlockID
+	}
+	return ""
+}
+
+// jsonString escapes s for use between the quotes of a JSON string
+func jsonString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return strings.TrimSuffix(strings.TrimPrefix(string(quoted), `"`), `"`)
+}
+
+func jsonValue(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/slack-go/slack"
+)
+
+const mockSurveyTemplate = `{
+    "text": "New survey: {{ str .Title }}",
+    "blocks": [
+        {
+            "type": "header",
+            "text": {"type": "plain_text", "text": "{{ str .Title }}"}
+        },
+        {
+            "type": "section",
+            "block_id": "survey_intro",
+            "text": {"type": "mrkdwn", "text": "{{ user .OwnerID }} asks: *{{ escape .Question }}*"}
+        },
+        {{ template "survey_button" . }}
+    ]
+}`
+
+const mockSurveyButtonTemplate = `{{ define "survey_button" }}{
+    "type": "actions",
+    "block_id": "survey_actions",
+    "elements": [
+        {
+            "type": "button",
+            "action_id": "answer",
+            "value": {{ json .ID }},
+            "text": {"type": "plain_text", "text": "Answer"}
+        }
+    ]
+}{{ end }}`
+
+type mockSurvey struct {
+	ID       string
+	Title    string
+	OwnerID  string
+	Question string
+	Repeat   []int
+}
+
+func TestMsgTemplatesRender(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/survey.json.tmpl":  {Data: []byte(mockSurveyTemplate)},
+		"templates/partials.tmpl":     {Data: []byte(mockSurveyButtonTemplate)},
+		"templates/bare.json.tmpl":    {Data: []byte(`[{"type": "divider"}]`)},
+		"templates/unknown.json.tmpl": {Data: []byte(`[{"type": "carousel"}]`)},
+		"templates/dupes.json.tmpl":   {Data: []byte(`[{"type": "divider", "block_id": "a"}, {"type": "divider", "block_id": "a"}]`)},
+		"templates/broken.json.tmpl":  {Data: []byte(`[{"type": "section", "text": {"type": "mrkdwn", "text": "{{ .Question }}"}}]`)},
+		"templates/many.json.tmpl":    {Data: []byte(`[{{ range $i, $_ := .Repeat }}{{ if $i }},{{ end }}{"type": "divider"}{{ end }}]`)},
+	}
+
+	templates, err := NewMsgTemplates(fsys, "templates/*.tmpl")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	survey := mockSurvey{
+		ID:       "survey-42",
+		Title:    `Lunch "poll"`,
+		OwnerID:  "U0G9QF9C6",
+		Question: "Pizza <or> sushi & salad?",
+	}
+
+	msg, err := templates.Render("survey.json.tmpl", survey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	btn := NewButton("answer", "survey-42", "Answer", slack.StyleDefault)
+	wantMsg := Msg{
+		Body: `New survey: Lunch "poll"`,
+		Blocks: []slack.Block{
+			slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, `Lunch "poll"`, false, false)),
+			&slack.SectionBlock{
+				Type:    slack.MBTSection,
+				BlockID: "survey_intro",
+				Text:    slack.NewTextBlockObject(slack.MarkdownType, "<@U0G9QF9C6> asks: *Pizza &lt;or&gt; sushi &amp; salad?*", false, false),
+			},
+			slack.NewActionBlock("survey_actions", btn),
+		},
+	}
+
+	if diff := pretty.Compare(msg, wantMsg); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+
+	msg, err = templates.Render("bare.json.tmpl", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(msg.Blocks) != 1 || msg.Blocks[0].BlockType() != slack.MBTDivider {
+		t.Fatalf("expected a single divider block, got: %v", msg.Blocks)
+	}
+
+	errCases := []struct {
+		name    string
+		data    interface{}
+		wantErr string
+	}{
+		{name: "unknown.json.tmpl", wantErr: `template unknown.json.tmpl produced invalid blocks: block 0 has unknown type "carousel"`},
+		{name: "dupes.json.tmpl", wantErr: `template dupes.json.tmpl produced invalid blocks: block 1 has duplicate block_id "a"`},
+		{name: "broken.json.tmpl", data: mockSurvey{Question: `say "hi"`}, wantErr: "template broken.json.tmpl produced invalid JSON"},
+		{name: "many.json.tmpl", data: mockSurvey{Repeat: make([]int, 51)}, wantErr: "template many.json.tmpl produced invalid blocks: 51 blocks exceeds the limit of 50"},
+		{name: "missing.json.tmpl", wantErr: "failed to render template"},
+	}
+
+	for _, tc := range errCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := templates.Render(tc.name, tc.data)
+			if err == nil {
+				t.Fatal("expected error but did not receive one")
+			}
+			if !strings.HasPrefix(err.Error(), tc.wantErr) {
+				t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMsgTemplatesDevMode(t *testing.T) {
+	fsys := fstest.MapFS{
+		"hello.json.tmpl": {Data: []byte(`{"text": "hello", "blocks": []}`)},
+	}
+
+	templates, err := NewMsgTemplates(fsys, "*.tmpl")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fsys["hello.json.tmpl"] = &fstest.MapFile{Data: []byte(`{"text": "goodbye", "blocks": []}`)}
+
+	msg, err := templates.Render("hello.json.tmpl", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Body != "hello" {
+		t.Fatalf("expected cached template to be used, got body: %s", msg.Body)
+	}
+
+	templates.DevMode = true
+	msg, err = templates.Render("hello.json.tmpl", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Body != "goodbye" {
+		t.Fatalf("expected template to be reloaded, got body: %s", msg.Body)
+	}
+}
+
+func TestNewMsgTemplatesParseFailure(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bad.json.tmpl": {Data: []byte(`{{ .Unclosed `)},
+	}
+
+	if _, err := NewMsgTemplates(fsys, "*.tmpl"); err == nil || !strings.HasPrefix(err.Error(), "failed to parse templates") {
+		t.Fatalf("expected parse error, got: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	PagePrevActionID = "page_prev"
+	PageNextActionID = "page_next"
+
+	// DefaultPageSize is the number of items shown per page if a PagedList's
+	// PageSize is not set
+	DefaultPageSize = 10
+)
+
+// PageAction is the page requested by clicking a PagedList's Prev or Next
+// button, as encoded in the button's value
+type PageAction struct {
+	ListID string `json:"id"`
+	Page   int    `json:"page"`
+	State  string `json:"state,omitempty"`
+}
+
+// PagedList is a message component for lists too long to fit in one message,
+// shown a page at a time with Prev and Next buttons. The requested page is
+// carried in the button values, so nothing needs to be stored between clicks:
+// the handler only needs to rebuild the list and call HandleAction.
+type PagedList struct {
+	// ID identifies the list in button values, so that HandleAction ignores
+	// clicks meant for other lists
+	ID string
+	// Count is the total number of items
+	Count int
+	// RenderItem returns the blocks showing the i-th item
+	RenderItem func(i int) []slack.Block
+	// PageSize is the number of items per page, DefaultPageSize if unset.
+	// Keep in mind the 50 block limit when items render to several blocks.
+	PageSize int
+	// Header blocks are shown above the items on every page
+	Header []slack.Block
+	// Body is the message's text, shown in notifications
+	Body string
+	// State is carried in the button values along with the page, e.g. the
+	// query the items were found with, for the handler to rebuild the list
+	// from. Page returns an error if it makes a button value longer than
+	// Slack's 2000 character limit.
+	State string
+}
+
+// Pages returns the number of pages in the list, at least one
+func (l *PagedList) Pages() int {
+	size := l.pageSize()
+	if l.Count <= size {
+		return 1
+	}
+	return (l.Count + size - 1) / size
+}
+
+// Page returns a message showing the given page (numbered from 0, and clamped
+// to the pages there are) with a page count and Prev and Next buttons
+func (l *PagedList) Page(page int) (Msg, error) {
+	if page >= l.Pages() {
+		page = l.Pages() - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	blocks := append([]slack.Block{}, l.Header...)
+
+	start := page * l.pageSize()
+	end := start + l.pageSize()
+	if end > l.Count {
+		end = l.Count
+	}
+	for i := start; i < end; i++ {
+		blocks = append(blocks, l.RenderItem(i)...)
+	}
+
+	footer := "No items"
+	if l.Count > 0 {
+		footer = fmt.Sprintf("Page %d of %d · %d items", page+1, l.Pages(), l.Count)
+	}
+	blocks = append(blocks, slack.NewContextBlock(l.ID+"_page_count", slack.NewTextBlockObject(slack.MarkdownType, footer, false, false)))
+
+	var buttons []slack.BlockElement
+	if page > 0 {
+		value, err := l.actionValue(page - 1)
+		if err != nil {
+			return Msg{}, err
+		}
+		buttons = append(buttons, NewButton(PagePrevActionID, value, "Prev", slack.StyleDefault))
+	}
+	if page < l.Pages()-1 {
+		value, err := l.actionValue(page + 1)
+		if err != nil {
+			return Msg{}, err
+		}
+		buttons = append(buttons, NewButton(PageNextActionID, value, "Next", slack.StyleDefault))
+	}
+	if len(buttons) > 0 {
+		blocks = append(blocks, slack.NewActionBlock(l.ID+"_pages", buttons...))
+	}
+
+	if err := ValidateBlocks(blocks); err != nil {
+		return Msg{}, errors.Wrapf(err, "page %d of list %s is invalid", page, l.ID)
+	}
+
+	return Msg{Body: l.Body, Blocks: blocks}, nil
+}
+
+// HandleAction re-renders the message with the page requested in a
+// block_actions callback, replacing it via the callback's response_url if
+// there is one (as there is for ephemeral messages) and UpdateMsg otherwise.
+// It returns false, doing nothing, if the callback is not a page action for
+// this list.
+func (l *PagedList) HandleAction(client *slack.Client, callback *slack.InteractionCallback) (bool, error) {
+	action, ok := PageActionFromCallback(callback)
+	if !ok || action.ListID != l.ID {
+		return false, nil
+	}
+
+	msg, err := l.Page(action.Page)
+	if err != nil {
+		return true, err
+	}
+
+	return true, replaceCallbackMsg(client, callback, msg)
+}
+
+// PageActionFromCallback returns the page requested in a block_actions
+// callback, if it was triggered by a PagedList's Prev or Next button. Use it
+// to read State before rebuilding the list.
+func PageActionFromCallback(callback *slack.InteractionCallback) (PageAction, bool) {
+	for _, action := range callback.ActionCallback.BlockActions {
+		if action.ActionID != PagePrevActionID && action.ActionID != PageNextActionID {
+			continue
+		}
+
+		var pageAction PageAction
+		if err := json.Unmarshal([]byte(action.Value), &pageAction); err != nil {
+			continue
+		}
+
+		return pageAction, true
+	}
+
+	return PageAction{}, false
+}
+
+func (l *PagedList) pageSize() int {
+	if l.PageSize <= 0 {
+		return DefaultPageSize
+	}
+	return l.PageSize
+}
+
+func (l *PagedList) actionValue(page int) (string, error) {
+	value, err := json.Marshal(PageAction{ListID: l.ID, Page: page, State: l.State})
+	if err != nil {
+		return "", err
+	}
+	if len(value) > buttonValueMaxLen {
+		return "", fmt.Errorf("page action of list %s is too large for a button value (%d characters)", l.ID, len(value))
+	}
+
+	return string(value), nil
+}
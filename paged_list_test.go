@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func newMockPagedList(count int) *PagedList {
+	return &PagedList{
+		ID:       "stale_channels",
+		Count:    count,
+		PageSize: 2,
+		Header:   []slack.Block{NewTextBlock("*Stale channels*", nil)},
+		RenderItem: func(i int) []slack.Block {
+			return []slack.Block{NewTextBlock(fmt.Sprintf("channel-%d", i), nil)}
+		},
+		State: "days=90",
+	}
+}
+
+func TestPagedListPage(t *testing.T) {
+	testCases := []struct {
+		description string
+		count       int
+		page        int
+		want        string
+	}{
+		{
+			description: "first page",
+			count:       5,
+			page:        0,
+			want:        "*Stale channels*\n\nchannel-0\n\nchannel-1\n\nPage 1 of 3 · 5 items\n\n[Next]",
+		},
+		{
+			description: "middle page",
+			count:       5,
+			page:        1,
+			want:        "*Stale channels*\n\nchannel-2\n\nchannel-3\n\nPage 2 of 3 · 5 items\n\n[Prev]  [Next]",
+		},
+		{
+			description: "page past the end is clamped to the last",
+			count:       5,
+			page:        7,
+			want:        "*Stale channels*\n\nchannel-4\n\nPage 3 of 3 · 5 items\n\n[Prev]",
+		},
+		{
+			description: "single page has no buttons",
+			count:       2,
+			page:        -1,
+			want:        "*Stale channels*\n\nchannel-0\n\nchannel-1\n\nPage 1 of 1 · 2 items",
+		},
+		{
+			description: "empty list",
+			count:       0,
+			want:        "*Stale channels*\n\nNo items",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			msg, err := newMockPagedList(tc.count).Page(tc.page)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := PreviewText(msg.Blocks); got != tc.want {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPagedListPageTooManyBlocks(t *testing.T) {
+	list := newMockPagedList(100)
+	list.PageSize = 50
+
+	wantErr := "page 0 of list stale_channels is invalid: 53 blocks exceeds the limit of 50"
+	if _, err := list.Page(0); err == nil || err.Error() != wantErr {
+		t.Fatalf("expected to receive error: %s, got: %v", wantErr, err)
+	}
+}
+
+func TestPagedListPageStateTooLarge(t *testing.T) {
+	list := newMockPagedList(5)
+	list.State = strings.Repeat("a", 2000)
+
+	wantErr := "page action of list stale_channels is too large for a button value (2043 characters)"
+	if _, err := list.Page(0); err == nil || err.Error() != wantErr {
+		t.Fatalf("expected to receive error: %s, got: %v", wantErr, err)
+	}
+
+	// A single page has no buttons to carry the state
+	list.Count = 2
+	if _, err := list.Page(0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPagedListHandleAction(t *testing.T) {
+	list := newMockPagedList(5)
+	first, err := list.Page(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	next := first.Blocks[len(first.Blocks)-1].(*slack.ActionBlock).Elements.ElementSet[0].(*slack.ButtonBlockElement)
+
+	newCallback := func(actionID, value, responseURL string) *slack.InteractionCallback {
+		callback := &slack.InteractionCallback{
+			Type:        slack.InteractionTypeBlockActions,
+			ResponseURL: responseURL,
+			Container:   slack.Container{ChannelID: "C1H9RESGL", MessageTs: "1503435956.000247"},
+		}
+		callback.ActionCallback.BlockActions = []*slack.BlockAction{{ActionID: actionID, Value: value}}
+		return callback
+	}
+
+	testCases := []struct {
+		description string
+		actionID    string
+		value       string
+		useRespURL  bool
+		wantHandled bool
+		wantPath    string
+	}{
+		{
+			description: "updates the message",
+			actionID:    next.ActionID,
+			value:       next.Value,
+			wantHandled: true,
+			wantPath:    "/chat.update",
+		},
+		{
+			description: "replaces the message via response_url",
+			actionID:    next.ActionID,
+			value:       next.Value,
+			useRespURL:  true,
+			wantHandled: true,
+			wantPath:    "/response",
+		},
+		{
+			description: "ignores other lists",
+			actionID:    PageNextActionID,
+			value:       `{"id":"survey_responses","page":1}`,
+		},
+		{
+			description: "ignores other actions",
+			actionID:    "archive",
+			value:       next.Value,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var paths []string
+			var body string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/chat.update", func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				_ = r.ParseForm()
+				body = r.FormValue("blocks")
+				_, _ = w.Write([]byte(mockUpdateMsgResp))
+			})
+			mux.HandleFunc("/response", func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				data, _ := ioutil.ReadAll(r.Body)
+				body = string(data)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"ok": true}`))
+			})
+
+			testServ := httptest.NewServer(mux)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+			var responseURL string
+			if tc.useRespURL {
+				responseURL = testServ.URL + "/response"
+			}
+
+			handled, err := list.HandleAction(client, newCallback(tc.actionID, tc.value, responseURL))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if handled != tc.wantHandled {
+				t.Fatalf("expected handled: %v, got: %v", tc.wantHandled, handled)
+			}
+
+			if tc.wantPath == "" {
+				if len(paths) != 0 {
+					t.Fatalf("expected no requests, got: %v", paths)
+				}
+				return
+			}
+
+			if len(paths) != 1 || paths[0] != tc.wantPath {
+				t.Fatalf("expected a request to %s, got: %v", tc.wantPath, paths)
+			}
+			if !strings.Contains(body, "channel-2") || strings.Contains(body, "channel-0") {
+				t.Fatalf("expected the second page to be sent, got: %s", body)
+			}
+		})
+	}
+}
+
+func TestPageActionFromCallback(t *testing.T) {
+	list := newMockPagedList(5)
+	msg, err := list.Page(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	prev := msg.Blocks[len(msg.Blocks)-1].(*slack.ActionBlock).Elements.ElementSet[0].(*slack.ButtonBlockElement)
+
+	var callback slack.InteractionCallback
+	callback.ActionCallback.BlockActions = []*slack.BlockAction{{ActionID: prev.ActionID, Value: prev.Value}}
+
+	action, ok := PageActionFromCallback(&callback)
+	if !ok {
+		t.Fatal("expected a page action")
+	}
+	if want := (PageAction{ListID: "stale_channels", Page: 0, State: "days=90"}); action != want {
+		t.Fatalf("expected action: %+v, got: %+v", want, action)
+	}
+}
@@ -0,0 +1,306 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alyosha/slack-utils/mrkdwn"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// BlockKitBuilderURL is the address of Block Kit Builder, to which the
+	// layout being previewed is appended as a URL fragment
+	BlockKitBuilderURL = "https://app.slack.com/block-kit-builder/"
+
+	// previewWidth is the width of dividers and header underlines in text
+	// previews
+	previewWidth = 40
+)
+
+// PreviewURL returns a Block Kit Builder URL showing the blocks, to open in a
+// browser when debugging a layout
+func PreviewURL(blocks []slack.Block) (string, error) {
+	return MsgPreviewURL(Msg{Blocks: blocks})
+}
+
+// MsgPreviewURL returns a Block Kit Builder URL showing the message's blocks
+// and attachments. A message with only a body is shown as a single section.
+func MsgPreviewURL(msg Msg) (string, error) {
+	payload := struct {
+		Blocks      []slack.Block      `json:"blocks"`
+		Attachments []slack.Attachment `json:"attachments,omitempty"`
+	}{
+		Blocks:      msg.Blocks,
+		Attachments: msg.Attachments,
+	}
+
+	if len(payload.Blocks) == 0 {
+		payload.Blocks = []slack.Block{}
+		if msg.Body != "" {
+			payload.Blocks = append(payload.Blocks, NewTextBlock(msg.Body, nil))
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	// Block Kit Builder decodes the fragment as encodeURIComponent would
+	// have encoded it, so spaces must be %20 rather than +
+	fragment := strings.ReplaceAll(url.QueryEscape(string(data)), "+", "%20")
+
+	return BlockKitBuilderURL + "#" + fragment, nil
+}
+
+// PreviewText renders the blocks as plain text, approximating how Slack lays
+// them out, for previewing messages in terminals and CI logs. Mentions are
+// shown by ID, and interactive elements in brackets: [Button], [Select v],
+// (o) radio buttons and [x] checkboxes.
+func PreviewText(blocks []slack.Block) string {
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		parts = append(parts, previewBlock(block))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// MsgPreviewText renders the message as PreviewText does, showing the body if
+// there are no blocks (as Slack does) and each attachment below it with a bar
+// down its left edge
+func MsgPreviewText(msg Msg) string {
+	text := PreviewText(msg.Blocks)
+	if len(msg.Blocks) == 0 {
+		text = previewMrkdwn(msg.Body)
+	}
+
+	parts := []string{text}
+	for _, attachment := range msg.Attachments {
+		var lines []string
+		for _, s := range []string{attachment.Pretext, attachment.Title, attachment.Text} {
+			if s != "" {
+				lines = append(lines, previewMrkdwn(s))
+			}
+		}
+		for _, field := range attachment.Fields {
+			lines = append(lines, previewMrkdwn(field.Title)+": "+previewMrkdwn(field.Value))
+		}
+		if len(attachment.Blocks.BlockSet) > 0 {
+			lines = append(lines, PreviewText(attachment.Blocks.BlockSet))
+		}
+		parts = append(parts, indent(strings.Join(lines, "\n"), "| "))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+func previewBlock(block slack.Block) string {
+	switch b := block.(type) {
+	case *slack.HeaderBlock:
+		title := previewTextObj(b.Text)
+		return title + "\n" + strings.Repeat("=", previewWidth)
+	case *slack.SectionBlock:
+		var lines []string
+		if b.Text != nil {
+			lines = append(lines, previewTextObj(b.Text))
+		}
+		for _, field := range b.Fields {
+			lines = append(lines, indent(previewTextObj(field), "  "))
+		}
+		if b.Accessory != nil {
+			if element := accessoryElement(b.Accessory); element != nil {
+				lines = append(lines, "  -> "+previewElement(element))
+			}
+		}
+		return strings.Join(lines, "\n")
+	case *slack.DividerBlock:
+		return strings.Repeat("-", previewWidth)
+	case *slack.ActionBlock:
+		return previewElements(b.Elements.ElementSet)
+	case *slack.ContextBlock:
+		var parts []string
+		for _, element := range b.ContextElements.Elements {
+			switch e := element.(type) {
+			case *slack.TextBlockObject:
+				parts = append(parts, previewTextObj(e))
+			case *slack.ImageBlockElement:
+				parts = append(parts, "[image: "+e.AltText+"]")
+			}
+		}
+		return strings.Join(parts, " | ")
+	case *slack.ImageBlock:
+		text := "[image: " + b.AltText + "]"
+		if b.Title != nil {
+			text = previewTextObj(b.Title) + "\n" + text
+		}
+		return text
+	case *slack.InputBlock:
+		label := previewTextObj(b.Label)
+		if b.Optional {
+			label += " (optional)"
+		}
+		lines := []string{label, previewElement(b.Element)}
+		if b.Hint != nil {
+			lines = append(lines, previewTextObj(b.Hint))
+		}
+		return strings.Join(lines, "\n")
+	case *slack.FileBlock:
+		return "[file: " + b.ExternalID + "]"
+	case *slack.VideoBlock:
+		title := b.AltText
+		if b.Title != nil {
+			title = previewTextObj(b.Title)
+		}
+		return "[video: " + title + "]"
+	case *slack.RichTextBlock:
+		return "[rich text]"
+	}
+
+	return fmt.Sprintf("[%s]", block.BlockType())
+}
+
+func previewElements(elements []slack.BlockElement) string {
+	parts := make([]string, 0, len(elements))
+	for _, element := range elements {
+		parts = append(parts, previewElement(element))
+	}
+	return strings.Join(parts, "  ")
+}
+
+func previewElement(element slack.BlockElement) string {
+	switch e := element.(type) {
+	case *slack.ButtonBlockElement:
+		return "[" + previewTextObj(e.Text) + "]"
+	case *slack.SelectBlockElement:
+		label := previewTextObj(e.Placeholder)
+		if e.InitialOption != nil {
+			label = previewTextObj(e.InitialOption.Text)
+		}
+		return "[" + label + " v]"
+	case *slack.MultiSelectBlockElement:
+		label := previewTextObj(e.Placeholder)
+		if len(e.InitialOptions) > 0 {
+			labels := make([]string, 0, len(e.InitialOptions))
+			for _, opt := range e.InitialOptions {
+				labels = append(labels, previewTextObj(opt.Text))
+			}
+			label = strings.Join(labels, ", ")
+		}
+		return "[" + label + " v]"
+	case *slack.OverflowBlockElement:
+		return "[...]"
+	case *slack.DatePickerBlockElement:
+		if value := previewValue(e.InitialDate, e.Placeholder); value != "" {
+			return "[" + value + "]"
+		}
+		return "[date]"
+	case *slack.TimePickerBlockElement:
+		if value := previewValue(e.InitialTime, e.Placeholder); value != "" {
+			return "[" + value + "]"
+		}
+		return "[time]"
+	case *slack.DateTimePickerBlockElement:
+		if e.InitialDateTime == 0 {
+			return "[date time]"
+		}
+		return "[" + time.Unix(e.InitialDateTime, 0).UTC().Format("2006-01-02 15:04 MST") + "]"
+	case *slack.PlainTextInputBlockElement:
+		return "[" + previewValue(e.InitialValue, e.Placeholder) + "_]"
+	case *slack.EmailTextInputBlockElement:
+		return "[" + previewValue(e.InitialValue, e.Placeholder) + "_]"
+	case *slack.URLTextInputBlockElement:
+		return "[" + previewValue(e.InitialValue, e.Placeholder) + "_]"
+	case *slack.NumberInputBlockElement:
+		return "[" + previewValue(e.InitialValue, e.Placeholder) + "_]"
+	case *slack.RadioButtonsBlockElement:
+		lines := make([]string, 0, len(e.Options))
+		for _, opt := range e.Options {
+			mark := "( )"
+			if e.InitialOption != nil && e.InitialOption.Value == opt.Value {
+				mark = "(o)"
+			}
+			lines = append(lines, mark+" "+previewTextObj(opt.Text))
+		}
+		return strings.Join(lines, "\n")
+	case *slack.CheckboxGroupsBlockElement:
+		checked := make(map[string]bool, len(e.InitialOptions))
+		for _, opt := range e.InitialOptions {
+			checked[opt.Value] = true
+		}
+		lines := make([]string, 0, len(e.Options))
+		for _, opt := range e.Options {
+			mark := "[ ]"
+			if checked[opt.Value] {
+				mark = "[x]"
+			}
+			lines = append(lines, mark+" "+previewTextObj(opt.Text))
+		}
+		return strings.Join(lines, "\n")
+	case *slack.ImageBlockElement:
+		return "[image: " + e.AltText + "]"
+	}
+
+	return fmt.Sprintf("[%s]", element.ElementType())
+}
+
+// accessoryElement returns whichever element the accessory holds
+func accessoryElement(accessory *slack.Accessory) slack.BlockElement {
+	switch {
+	case accessory.ImageElement != nil:
+		return accessory.ImageElement
+	case accessory.ButtonElement != nil:
+		return accessory.ButtonElement
+	case accessory.OverflowElement != nil:
+		return accessory.OverflowElement
+	case accessory.DatePickerElement != nil:
+		return accessory.DatePickerElement
+	case accessory.TimePickerElement != nil:
+		return accessory.TimePickerElement
+	case accessory.PlainTextInputElement != nil:
+		return accessory.PlainTextInputElement
+	case accessory.RadioButtonsElement != nil:
+		return accessory.RadioButtonsElement
+	case accessory.SelectElement != nil:
+		return accessory.SelectElement
+	case accessory.MultiSelectElement != nil:
+		return accessory.MultiSelectElement
+	case accessory.CheckboxGroupsBlockElement != nil:
+		return accessory.CheckboxGroupsBlockElement
+	case accessory.UnknownElement != nil:
+		return accessory.UnknownElement
+	}
+	return nil
+}
+
+// previewValue returns an element's value, or its placeholder if it has none
+func previewValue(value string, placeholder *slack.TextBlockObject) string {
+	if value != "" {
+		return value
+	}
+	return previewTextObj(placeholder)
+}
+
+func previewTextObj(obj *slack.TextBlockObject) string {
+	if obj == nil {
+		return ""
+	}
+	if obj.Type == slack.MarkdownType {
+		return previewMrkdwn(obj.Text)
+	}
+	return obj.Text
+}
+
+// previewMrkdwn unescapes mrkdwn text and replaces mentions, dates and links
+// with their readable form, leaving formatting characters in place
+func previewMrkdwn(text string) string {
+	return mrkdwn.PlainText(mrkdwn.Parse(text), nil)
+}
+
+func indent(text, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
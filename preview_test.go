@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func mockPreviewBlocks() []slack.Block {
+	sizeOpts := []*slack.OptionBlockObject{
+		slack.NewOptionBlockObject("s", slack.NewTextBlockObject(slack.PlainTextType, "Small", false, false), nil),
+		slack.NewOptionBlockObject("l", slack.NewTextBlockObject(slack.PlainTextType, "Large", false, false), nil),
+	}
+
+	return []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Lunch order", false, false)),
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, "<@U0G9QF9C6> is ordering from <https://example.com/menu|the menu> &amp; more", false, false),
+			[]*slack.TextBlockObject{slack.NewTextBlockObject(slack.MarkdownType, "*Budget*\n$15", false, false)},
+			slack.NewAccessory(NewButton("view_menu", "menu", "View menu", slack.StyleDefault)),
+		),
+		slack.NewDividerBlock(),
+		slack.NewActionBlock(
+			"order_actions",
+			NewButton("order", "order", "Order", slack.StylePrimary),
+			&slack.SelectBlockElement{Type: slack.OptTypeStatic, ActionID: "size", Placeholder: slack.NewTextBlockObject(slack.PlainTextType, "Size", false, false), Options: sizeOpts},
+			slack.NewDatePickerBlockElement("day"),
+		),
+		slack.NewInputBlock(
+			"size_input",
+			slack.NewTextBlockObject(slack.PlainTextType, "Size", false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, "Pick one", false, false),
+			&slack.RadioButtonsBlockElement{Type: slack.METRadioButtons, ActionID: "size", Options: sizeOpts, InitialOption: sizeOpts[1]},
+		),
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, "Orders close at noon", false, false), slack.NewImageBlockElement("https://example.com/clock.png", "clock")),
+	}
+}
+
+func TestPreviewText(t *testing.T) {
+	want := strings.Join([]string{
+		"Lunch order",
+		"========================================",
+		"",
+		"@U0G9QF9C6 is ordering from the menu & more",
+		"  *Budget*",
+		"  $15",
+		"  -> [View menu]",
+		"",
+		"----------------------------------------",
+		"",
+		"[Order]  [Size v]  [date]",
+		"",
+		"Size",
+		"( ) Small",
+		"(o) Large",
+		"Pick one",
+		"",
+		"Orders close at noon | [image: clock]",
+	}, "\n")
+
+	if got := PreviewText(mockPreviewBlocks()); got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestMsgPreviewText(t *testing.T) {
+	msg := Msg{
+		Body: "Lunch &lt;today&gt;",
+		Attachments: []slack.Attachment{
+			{
+				Title:  "Order summary",
+				Fields: []slack.AttachmentField{{Title: "Total", Value: "$12"}},
+			},
+		},
+	}
+
+	want := "Lunch <today>\n\n| Order summary\n| Total: $12"
+	if got := MsgPreviewText(msg); got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestPreviewURL(t *testing.T) {
+	previewURL, err := PreviewURL(mockPreviewBlocks())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(previewURL, BlockKitBuilderURL+"#") {
+		t.Fatalf("expected url to start with %s#, got: %s", BlockKitBuilderURL, previewURL)
+	}
+	if strings.ContainsAny(previewURL, " +") {
+		t.Fatalf("expected spaces to be percent-encoded, got: %s", previewURL)
+	}
+
+	fragment, err := url.PathUnescape(strings.TrimPrefix(previewURL, BlockKitBuilderURL+"#"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var payload struct {
+		Blocks slack.Blocks `json:"blocks"`
+	}
+	if err := json.Unmarshal([]byte(fragment), &payload); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := PreviewText(payload.Blocks.BlockSet); got != PreviewText(mockPreviewBlocks()) {
+		t.Fatalf("expected url to round trip the blocks, got:\n%s", got)
+	}
+
+	previewURL, err = MsgPreviewURL(Msg{Body: "hello world"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := BlockKitBuilderURL + "#%7B%22blocks%22%3A%5B%7B%22type%22%3A%22section%22%2C%22text%22%3A%7B%22type%22%3A%22mrkdwn%22%2C%22text%22%3A%22hello%20world%22%7D%7D%5D%7D"
+	if previewURL != want {
+		t.Fatalf("expected: %s, got: %s", want, previewURL)
+	}
+}
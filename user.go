@@ -1,87 +1,241 @@
 package utils
 
 import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/slack-go/slack"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// defaultEmailLookupThreshold is the largest number of emails resolved
+	// through users.lookupByEmail rather than by listing every user
+	defaultEmailLookupThreshold   = 20
+	defaultEmailLookupConcurrency = 5
 )
 
+const errUsersNotFoundMsg = "users_not_found"
+
+// EmailResolution reports how each of a list of emails was resolved to a
+// workspace member. Emails are normalized (trimmed and lowercased) and
+// deduplicated, and appear in the order they were first given.
+type EmailResolution struct {
+	// Matched lists emails belonging to active, human users
+	Matched []EmailMatch
+	// Unmatched lists emails which do not belong to any user
+	Unmatched []string
+	// Deactivated lists emails belonging to deactivated users
+	Deactivated []EmailMatch
+	// Bots lists emails belonging to bot users
+	Bots []EmailMatch
+	// Filtered lists emails belonging to users excluded by a UserFilter
+	Filtered []EmailMatch
+}
+
+// EmailMatch is an email along with the ID of the user it belongs to
+type EmailMatch struct {
+	Email  string
+	UserID string
+}
+
+// IDs returns the IDs of the matched users
+func (r *EmailResolution) IDs() []string {
+	ids := make([]string, 0, len(r.Matched))
+	for _, match := range r.Matched {
+		ids = append(ids, match.UserID)
+	}
+
+	return ids
+}
+
+// EmailResolver resolves emails to workspace members. Inputs of up to
+// LookupThreshold emails are looked up one at a time through
+// users.lookupByEmail, with up to Concurrency lookups in flight, while larger
+// inputs list every user once through a UserDirectory. Zero values use
+// defaults of 20 and 5; set LookupThreshold below zero to always list users.
+type EmailResolver struct {
+	Client          *slack.Client
+	LookupThreshold int
+	Concurrency     int
+}
+
 // EmailsToSlackIDs takes in an array of email addresses and finds the IDs of
-// any workplace members with those emails
-func EmailsToSlackIDs(client *slack.Client, emails []string) ([]string, error) {
-	users, err := getAll(client)
+// any active, human workplace members with those emails, in the order of the
+// emails and passing all of the filters. Use EmailsToSlackIDsInclusive to find
+// out which emails were not matched, or a UserDirectory when converting
+// repeatedly.
+func EmailsToSlackIDs(client *slack.Client, emails []string, filters ...UserFilter) ([]string, error) {
+	r := &EmailResolver{Client: client}
+	return r.EmailsToSlackIDs(emails, filters...)
+}
+
+// EmailToSlackIDsInclusive takes in an array of email addresses and reports
+// which of them belong to active users, deactivated users or bots, and which
+// match no one at all
+func EmailsToSlackIDsInclusive(client *slack.Client, emails []string, filters ...UserFilter) (*EmailResolution, error) {
+	r := &EmailResolver{Client: client}
+	return r.EmailsToSlackIDsInclusive(emails, filters...)
+}
+
+// EmailsToSlackIDs returns the IDs of any active, human users with the given
+// emails and passing all of the filters, in the order of the emails
+func (r *EmailResolver) EmailsToSlackIDs(emails []string, filters ...UserFilter) ([]string, error) {
+	resolution, err := r.EmailsToSlackIDsInclusive(emails, filters...)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(users) == 0 {
-		return nil, ErrNoUsersInWorkplace
+	return resolution.IDs(), nil
+}
+
+// EmailsToSlackIDsInclusive reports which of the emails belong to active
+// users, deactivated users or bots, and which match no one at all
+func (r *EmailResolver) EmailsToSlackIDsInclusive(emails []string, filters ...UserFilter) (*EmailResolution, error) {
+	threshold := r.LookupThreshold
+	if threshold == 0 {
+		threshold = defaultEmailLookupThreshold
 	}
 
-	return toSlackIDs(users, emails), nil
-}
+	normalized := normalizeEmails(emails)
+	if len(normalized) == 0 {
+		return &EmailResolution{}, nil
+	}
+
+	if len(normalized) > threshold {
+		return NewUserDirectory(r.Client, 0).EmailsToSlackIDsInclusive(normalized, filters...)
+	}
 
-// EmailToSlackIDsInclusive takes in an array of email addresses, finds the IDs
-// of any workplace members with those emails, and returns both values
-func EmailsToSlackIDsInclusive(client *slack.Client, emails []string) ([][]string, error) {
-	users, err := getAll(client)
+	users, err := r.lookupByEmail(normalized)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(users) == 0 {
-		return nil, ErrNoUsersInWorkplace
+	return resolveEmails(normalized, func(email string) (*slack.User, error) {
+		user, ok := users[email]
+		if !ok {
+			return nil, ErrUserNotFound
+		}
+		return user, nil
+	}, filters)
+}
+
+// lookupByEmail looks up each of the emails concurrently, returning the users
+// found keyed by email
+func (r *EmailResolver) lookupByEmail(emails []string) (map[string]*slack.User, error) {
+	concurrency := r.Concurrency
+	if concurrency < 1 {
+		concurrency = defaultEmailLookupConcurrency
 	}
 
-	return toSlackIDsInclusive(users, emails), nil
-}
+	var mu sync.Mutex
+	users := make(map[string]*slack.User, len(emails))
+	sem := make(chan struct{}, concurrency)
+	eg, ctx := errgroup.WithContext(context.Background())
 
-func toSlackIDs(users []slack.User, emails []string) []string {
-	var ids []string
 	for _, email := range emails {
-		for _, user := range users {
-			if user.Profile.Email == email {
-				ids = append(ids, user.ID)
+		email := email
+		sem <- struct{}{}
+		eg.Go(func() error {
+			defer func() { <-sem }()
+
+			var user *slack.User
+			err := callWithRetry(ctx, func() (err error) {
+				user, err = r.Client.GetUserByEmail(email)
+				return err
+			})
+			if err != nil && err.Error() == errUsersNotFoundMsg {
+				return nil
 			}
-		}
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			users[email] = user
+			mu.Unlock()
+			return nil
+		})
 	}
 
-	return ids
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
 }
 
-func toSlackIDsInclusive(users []slack.User, emails []string) [][]string {
-	var emailIDPairs [][]string
-	for _, email := range emails {
-		for _, user := range users {
-			if user.Profile.Email == email {
-				emailIDPairs = append(emailIDPairs, []string{email, user.ID})
-			}
-		}
+// UserLocation returns the timezone set in the user's profile, for use with
+// the timezone-aware date and time picker helpers
+func UserLocation(client *slack.Client, userID string) (*time.Location, error) {
+	user, err := client.GetUserInfo(userID)
+	if err != nil {
+		return nil, err
 	}
 
-	return emailIDPairs
+	return userLocation(*user), nil
 }
 
-func toEmails(users []slack.User, userIDs []string) []string {
-	var emails []string
-	for _, id := range userIDs {
-		for _, user := range users {
-			if user.ID == id && user.Profile.Email != "" {
-				emails = append(emails, user.Profile.Email)
-			}
+// userLocation loads the user's IANA timezone, falling back to a fixed zone
+// at the user's UTC offset if it is not known to the local tz database
+func userLocation(user slack.User) *time.Location {
+	if user.TZ != "" {
+		if loc, err := time.LoadLocation(user.TZ); err == nil {
+			return loc
 		}
 	}
 
-	return emails
+	return time.FixedZone(user.TZLabel, user.TZOffset)
 }
 
-func getAll(client *slack.Client) ([]slack.User, error) {
-	users, err := client.GetUsers()
-	if err != nil {
-		return nil, err
+// resolveEmails sorts the normalized emails into a resolution using lookup,
+// which returns ErrUserNotFound for emails not belonging to any user
+func resolveEmails(emails []string, lookup func(email string) (*slack.User, error), filters []UserFilter) (*EmailResolution, error) {
+	resolution := &EmailResolution{}
+	for _, email := range normalizeEmails(emails) {
+		user, err := lookup(email)
+		if err == ErrUserNotFound {
+			resolution.Unmatched = append(resolution.Unmatched, email)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		match := EmailMatch{Email: email, UserID: user.ID}
+		switch {
+		case user.Deleted:
+			resolution.Deactivated = append(resolution.Deactivated, match)
+		case user.IsBot:
+			resolution.Bots = append(resolution.Bots, match)
+		case !matchAll(filters, *user):
+			resolution.Filtered = append(resolution.Filtered, match)
+		default:
+			resolution.Matched = append(resolution.Matched, match)
+		}
 	}
 
-	if len(users) == 0 {
-		return nil, ErrNoUsersInWorkplace
+	return resolution, nil
+}
+
+// normalizeEmails trims and lowercases the emails, dropping blanks and
+// duplicates
+func normalizeEmails(emails []string) []string {
+	var normalized []string
+	seen := make(map[string]struct{}, len(emails))
+	for _, email := range emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" {
+			continue
+		}
+		if _, ok := seen[email]; ok {
+			continue
+		}
+		seen[email] = struct{}{}
+		normalized = append(normalized, email)
 	}
 
-	return users, nil
+	return normalized
 }
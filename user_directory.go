@@ -0,0 +1,350 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// usersPageSize is the page size used when paging through users.list
+const usersPageSize = 200
+
+var ErrUserNotFound = errors.New("user not found")
+
+// UserDirectory holds every member of the workspace in memory, indexed by ID,
+// email and display name, so that repeated lookups need a single users.list
+// scan. Users are loaded on first use and reloaded once TTL has passed (never,
+// if TTL is zero). Pass user_change and team_join events to HandleEvent to keep
+// the directory current in between loads. It is safe for concurrent use.
+type UserDirectory struct {
+	Client *slack.Client
+	TTL    time.Duration
+
+	mu       sync.RWMutex
+	loadedAt time.Time
+	byID     map[string]slack.User
+	// byEmail and byName are keyed by the lowercased email and display name
+	byEmail map[string]string
+	byName  map[string][]string
+}
+
+// NewUserDirectory returns a directory backed by the client, reloading users
+// once the ttl has passed
+func NewUserDirectory(client *slack.Client, ttl time.Duration) *UserDirectory {
+	return &UserDirectory{
+		Client: client,
+		TTL:    ttl,
+	}
+}
+
+// Refresh reloads every user in the workspace, regardless of the TTL
+func (d *UserDirectory) Refresh() error {
+	users, err := d.Client.GetUsers(slack.GetUsersOptionLimit(usersPageSize))
+	if err != nil {
+		return err
+	}
+
+	if len(users) == 0 {
+		return ErrNoUsersInWorkplace
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.byID = make(map[string]slack.User, len(users))
+	d.byEmail = make(map[string]string, len(users))
+	d.byName = make(map[string][]string, len(users))
+	for _, user := range users {
+		d.index(user)
+	}
+	d.loadedAt = time.Now()
+
+	return nil
+}
+
+// Users returns every user in the workspace, in no particular order
+func (d *UserDirectory) Users() ([]slack.User, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	users := make([]slack.User, 0, len(d.byID))
+	for _, user := range d.byID {
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// UserByID returns the user with the given ID, or ErrUserNotFound
+func (d *UserDirectory) UserByID(userID string) (*slack.User, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	user, ok := d.byID[userID]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	return &user, nil
+}
+
+// UserByEmail returns the user with the given email, compared
+// case-insensitively, or ErrUserNotFound
+func (d *UserDirectory) UserByEmail(email string) (*slack.User, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	user, ok := d.byID[d.byEmail[strings.ToLower(email)]]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	return &user, nil
+}
+
+// DisplayName returns the name the user with the given ID is shown under in
+// Slack: their display name, or real name if they have not set one. It
+// satisfies mrkdwn.UserNamer, for rendering mentions as plain text.
+func (d *UserDirectory) DisplayName(userID string) (string, error) {
+	user, err := d.UserByID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case user.Profile.DisplayName != "":
+		return user.Profile.DisplayName, nil
+	case user.Profile.RealName != "":
+		return user.Profile.RealName, nil
+	}
+
+	return user.Name, nil
+}
+
+// UsersByDisplayName returns the users with the given display name, compared
+// case-insensitively. Display names are not unique, so several users may be
+// returned.
+func (d *UserDirectory) UsersByDisplayName(name string) ([]slack.User, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ids := d.byName[strings.ToLower(name)]
+	if len(ids) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	users := make([]slack.User, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, d.byID[id])
+	}
+
+	return users, nil
+}
+
+// UserLocation returns the timezone set in the profile of the user with the
+// given ID, or ErrUserNotFound
+func (d *UserDirectory) UserLocation(userID string) (*time.Location, error) {
+	user, err := d.UserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return userLocation(*user), nil
+}
+
+// EmailsToSlackIDs returns the IDs of any active, human users with the given
+// emails and passing all of the filters, in the order of the emails
+func (d *UserDirectory) EmailsToSlackIDs(emails []string, filters ...UserFilter) ([]string, error) {
+	resolution, err := d.EmailsToSlackIDsInclusive(emails, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolution.IDs(), nil
+}
+
+// EmailsToSlackIDsInclusive reports which of the emails belong to active
+// users, deactivated users or bots, and which match no one at all
+func (d *UserDirectory) EmailsToSlackIDsInclusive(emails []string, filters ...UserFilter) (*EmailResolution, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	return resolveEmails(emails, d.UserByEmail, filters)
+}
+
+// SlackIDsToEmails returns the emails of any users with the given IDs, in the
+// order of the IDs. Users without a visible email, or not passing all of the
+// filters, are left out.
+func (d *UserDirectory) SlackIDsToEmails(userIDs []string, filters ...UserFilter) ([]string, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	var emails []string
+	for _, id := range userIDs {
+		user, err := d.UserByID(id)
+		if err == ErrUserNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if user.Profile.Email != "" && matchAll(filters, *user) {
+			emails = append(emails, user.Profile.Email)
+		}
+	}
+
+	return emails, nil
+}
+
+// FilterIDs returns the IDs of the users passing all of the filters, in the
+// order given. IDs not belonging to any user are left out.
+func (d *UserDirectory) FilterIDs(userIDs []string, filters ...UserFilter) ([]string, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var ids []string
+	for _, id := range userIDs {
+		if user, ok := d.byID[id]; ok && matchAll(filters, user) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// HandleEvent updates the directory from user_change and team_join events,
+// whether received over RTM/Socket Mode or the Events API. slackevents can't
+// parse user_change events, so Events API callers should pass the callback's
+// raw InnerEvent instead. Other events are ignored, as are all events
+// received before the directory is first loaded.
+func (d *UserDirectory) HandleEvent(event interface{}) {
+	switch ev := event.(type) {
+	case *json.RawMessage:
+		if ev != nil {
+			d.handleRawEvent(*ev)
+		}
+	case json.RawMessage:
+		d.handleRawEvent(ev)
+	case *slack.UserChangeEvent:
+		d.UpdateUser(ev.User)
+	case *slack.TeamJoinEvent:
+		d.UpdateUser(ev.User)
+	case *slackevents.TeamJoinEvent:
+		if ev.User != nil {
+			d.UpdateUser(*ev.User)
+		}
+	}
+}
+
+// handleRawEvent decodes an Events API inner event and handles it if it is
+// one HandleEvent applies
+func (d *UserDirectory) handleRawEvent(data json.RawMessage) {
+	var event struct {
+		Type string     `json:"type"`
+		User slack.User `json:"user"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return
+	}
+
+	switch event.Type {
+	case "user_change", "team_join":
+		d.UpdateUser(event.User)
+	}
+}
+
+// UpdateUser adds the user to the directory or replaces its existing entry
+func (d *UserDirectory) UpdateUser(user slack.User) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.byID == nil {
+		return
+	}
+
+	if old, ok := d.byID[user.ID]; ok {
+		d.unindex(old)
+	}
+	d.index(user)
+}
+
+// load loads users if they have not been loaded yet or the TTL has passed
+func (d *UserDirectory) load() error {
+	d.mu.RLock()
+	fresh := d.byID != nil && (d.TTL <= 0 || time.Since(d.loadedAt) < d.TTL)
+	d.mu.RUnlock()
+
+	if fresh {
+		return nil
+	}
+
+	return d.Refresh()
+}
+
+func (d *UserDirectory) index(user slack.User) {
+	d.byID[user.ID] = user
+
+	// An email may be shared by a deactivated account and its active
+	// replacement, in which case the active one wins
+	if user.Profile.Email != "" {
+		email := strings.ToLower(user.Profile.Email)
+		if existing, ok := d.byID[d.byEmail[email]]; !ok || existing.Deleted || !user.Deleted {
+			d.byEmail[email] = user.ID
+		}
+	}
+
+	if user.Profile.DisplayName != "" {
+		name := strings.ToLower(user.Profile.DisplayName)
+		d.byName[name] = append(d.byName[name], user.ID)
+	}
+}
+
+func (d *UserDirectory) unindex(user slack.User) {
+	delete(d.byID, user.ID)
+
+	email := strings.ToLower(user.Profile.Email)
+	if d.byEmail[email] == user.ID {
+		delete(d.byEmail, email)
+	}
+
+	name := strings.ToLower(user.Profile.DisplayName)
+	ids := d.byName[name]
+	for i, id := range ids {
+		if id == user.ID {
+			ids = append(ids[:i:i], ids[i+1:]...)
+			break
+		}
+	}
+	if len(ids) == 0 {
+		delete(d.byName, name)
+	} else {
+		d.byName[name] = ids
+	}
+}
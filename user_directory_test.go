@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alyosha/slack-utils/mrkdwn"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func newUserDirectoryServer(respUsersList []byte, calls *int) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		_, _ = w.Write(respUsersList)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestUserDirectoryLookups(t *testing.T) {
+	var calls int
+	testServ := newUserDirectoryServer([]byte(mockUsersListResp), &calls)
+	defer testServ.Close()
+
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+	directory := NewUserDirectory(client, 0)
+
+	user, err := directory.UserByID("W07QCRPA4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.Name != "glinda" {
+		t.Fatalf("expected user: glinda, got: %s", user.Name)
+	}
+
+	user, err = directory.UserByEmail("Spengler@Ghostbusters.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.ID != "U0G9QF9C6" {
+		t.Fatalf("expected user id: U0G9QF9C6, got: %s", user.ID)
+	}
+
+	users, err := directory.UsersByDisplayName("glinda the fairly good")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 1 || users[0].ID != "W07QCRPA4" {
+		t.Fatalf("expected user W07QCRPA4, got: %v", users)
+	}
+
+	if _, err := directory.UserByID("UNKNOWN01"); err != ErrUserNotFound {
+		t.Fatalf("expected to receive error: %s, got: %v", ErrUserNotFound, err)
+	}
+
+	if _, err := directory.UserByEmail("venkman@ghostbusters.example.com"); err != ErrUserNotFound {
+		t.Fatalf("expected to receive error: %s, got: %v", ErrUserNotFound, err)
+	}
+
+	emails, err := directory.SlackIDsToEmails([]string{"W07QCRPA4", "UNKNOWN01", "U0G9QF9C6"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(emails) != 2 || emails[0] != "glenda@south.oz.coven" || emails[1] != "spengler@ghostbusters.example.com" {
+		t.Fatalf("expected emails for W07QCRPA4 and U0G9QF9C6, got: %v", emails)
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected users to be listed once, got %v calls", calls)
+	}
+}
+
+func TestUserDirectoryTTL(t *testing.T) {
+	var calls int
+	testServ := newUserDirectoryServer([]byte(mockUsersListResp), &calls)
+	defer testServ.Close()
+
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+	directory := NewUserDirectory(client, time.Hour)
+
+	if _, err := directory.Users(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := directory.Users(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected users to be listed once within the ttl, got %v calls", calls)
+	}
+
+	directory.loadedAt = time.Now().Add(-2 * time.Hour)
+	if _, err := directory.Users(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected users to be listed again after the ttl, got %v calls", calls)
+	}
+}
+
+func TestUserDirectoryFailure(t *testing.T) {
+	var calls int
+	testServ := newUserDirectoryServer([]byte(mockUsersListErrResp), &calls)
+	defer testServ.Close()
+
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+	directory := NewUserDirectory(client, 0)
+
+	if _, err := directory.UserByID("U0G9QF9C6"); err == nil || err.Error() != "invalid_cursor" {
+		t.Fatalf("expected to receive error: invalid_cursor, got: %v", err)
+	}
+}
+
+func TestUserDirectoryHandleEvent(t *testing.T) {
+	var calls int
+	testServ := newUserDirectoryServer([]byte(mockUsersListResp), &calls)
+	defer testServ.Close()
+
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+	directory := NewUserDirectory(client, 0)
+
+	if err := directory.Refresh(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changed, err := directory.UserByID("U0G9QF9C6")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changed.Profile.Email = "egon@ghostbusters.example.com"
+	changed.Profile.DisplayName = "egon"
+	directory.HandleEvent(&slack.UserChangeEvent{Type: "user_change", User: *changed})
+
+	if _, err := directory.UserByEmail("spengler@ghostbusters.example.com"); err != ErrUserNotFound {
+		t.Fatalf("expected old email to be removed, got: %v", err)
+	}
+	if _, err := directory.UsersByDisplayName("spengler"); err != ErrUserNotFound {
+		t.Fatalf("expected old display name to be removed, got: %v", err)
+	}
+	if user, err := directory.UserByEmail("egon@ghostbusters.example.com"); err != nil || user.ID != "U0G9QF9C6" {
+		t.Fatalf("expected new email to resolve to U0G9QF9C6, got: %v, %v", user, err)
+	}
+
+	joined := slack.User{ID: "U0NEWUSER", Name: "venkman", Profile: slack.UserProfile{Email: "venkman@ghostbusters.example.com"}}
+	directory.HandleEvent(&slackevents.TeamJoinEvent{Type: "team_join", User: &joined})
+
+	if user, err := directory.UserByEmail("venkman@ghostbusters.example.com"); err != nil || user.ID != "U0NEWUSER" {
+		t.Fatalf("expected new user to resolve to U0NEWUSER, got: %v, %v", user, err)
+	}
+
+	// Events API callbacks carry user_change events slackevents can't parse
+	var callback slackevents.EventsAPICallbackEvent
+	if err := json.Unmarshal([]byte(`{
+		"type": "event_callback",
+		"event": {"type": "user_change", "user": {"id": "U0NEWUSER", "name": "venkman", "profile": {"email": "peter@ghostbusters.example.com"}}}
+	}`), &callback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	directory.HandleEvent(callback.InnerEvent)
+
+	if user, err := directory.UserByEmail("peter@ghostbusters.example.com"); err != nil || user.ID != "U0NEWUSER" {
+		t.Fatalf("expected changed email to resolve to U0NEWUSER, got: %v, %v", user, err)
+	}
+	if _, err := directory.UserByEmail("venkman@ghostbusters.example.com"); err != ErrUserNotFound {
+		t.Fatalf("expected old email to be removed, got: %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected events to be applied without listing users again, got %v calls", calls)
+	}
+}
+
+func TestUserDirectoryDisplayName(t *testing.T) {
+	var calls int
+	testServ := newUserDirectoryServer([]byte(mockUsersListResp), &calls)
+	defer testServ.Close()
+
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+	var namer mrkdwn.UserNamer = NewUserDirectory(client, 0)
+
+	text := mrkdwn.PlainText(mrkdwn.Parse("<@W07QCRPA4> paged <@U0G9QF9C6> and <@UNKNOWN01|venkman>"), namer)
+	if want := "@Glinda the Fairly Good paged @spengler and @venkman"; text != want {
+		t.Fatalf("expected: %q, got: %q", want, text)
+	}
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"github.com/slack-go/slack"
+)
+
+// UserFilter narrows down the users returned by the user and channel member
+// helpers. A zero value lets every user through; when several filters are
+// passed a user must satisfy all of them.
+type UserFilter struct {
+	// ExcludeDeleted drops deactivated users
+	ExcludeDeleted bool
+	// ExcludeBots drops bot and app users
+	ExcludeBots bool
+	// ExcludeGuests drops single and multi-channel guests
+	ExcludeGuests bool
+	// OnlyTimezone keeps only users in the given IANA timezone (e.g. Asia/Tokyo)
+	OnlyTimezone string
+	// Predicate, if set, must return true for the user to be kept
+	Predicate func(user slack.User) bool
+}
+
+// Match reports whether the user passes the filter
+func (f UserFilter) Match(user slack.User) bool {
+	switch {
+	case f.ExcludeDeleted && user.Deleted:
+		return false
+	case f.ExcludeBots && (user.IsBot || user.IsAppUser):
+		return false
+	case f.ExcludeGuests && (user.IsRestricted || user.IsUltraRestricted):
+		return false
+	case f.OnlyTimezone != "" && user.TZ != f.OnlyTimezone:
+		return false
+	case f.Predicate != nil && !f.Predicate(user):
+		return false
+	}
+
+	return true
+}
+
+func matchAll(filters []UserFilter, user slack.User) bool {
+	for _, filter := range filters {
+		if !filter.Match(user) {
+			return false
+		}
+	}
+
+	return true
+}
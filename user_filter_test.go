@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/slack-go/slack"
+)
+
+func TestUserFilterMatch(t *testing.T) {
+	active := slack.User{ID: "U0G9QF9C6", TZ: "America/Los_Angeles"}
+	deleted := slack.User{ID: "U0STANTZ1", Deleted: true}
+	bot := slack.User{ID: "U0SLIMER1", IsBot: true}
+	app := slack.User{ID: "U0APPUSER", IsAppUser: true}
+	guest := slack.User{ID: "U0GUEST01", IsRestricted: true}
+	singleChannelGuest := slack.User{ID: "U0GUEST02", IsUltraRestricted: true}
+
+	testCases := []struct {
+		description string
+		filter      UserFilter
+		user        slack.User
+		want        bool
+	}{
+		{description: "zero filter keeps deleted user", user: deleted, want: true},
+		{description: "deleted user excluded", filter: UserFilter{ExcludeDeleted: true}, user: deleted},
+		{description: "active user kept", filter: UserFilter{ExcludeDeleted: true, ExcludeBots: true, ExcludeGuests: true}, user: active, want: true},
+		{description: "bot user excluded", filter: UserFilter{ExcludeBots: true}, user: bot},
+		{description: "app user excluded", filter: UserFilter{ExcludeBots: true}, user: app},
+		{description: "multi-channel guest excluded", filter: UserFilter{ExcludeGuests: true}, user: guest},
+		{description: "single-channel guest excluded", filter: UserFilter{ExcludeGuests: true}, user: singleChannelGuest},
+		{description: "user in timezone kept", filter: UserFilter{OnlyTimezone: "America/Los_Angeles"}, user: active, want: true},
+		{description: "user in other timezone excluded", filter: UserFilter{OnlyTimezone: "Asia/Tokyo"}, user: active},
+		{
+			description: "predicate applied",
+			filter:      UserFilter{Predicate: func(user slack.User) bool { return user.ID != "U0G9QF9C6" }},
+			user:        active,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := tc.filter.Match(tc.user); got != tc.want {
+				t.Fatalf("expected match: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGetChannelMembersFiltered(t *testing.T) {
+	mux := newUsersMux([]byte(mockUsersListInactiveResp), nil)
+	mux.HandleFunc("/conversations.members", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok": true, "members": ["U0G9QF9C6", "U0STANTZ1", "U0SLIMER1", "U0UNKNOWN"]}`))
+	})
+
+	testServ := httptest.NewServer(mux)
+	defer testServ.Close()
+
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+	ids, err := GetChannelMembers(client, "C1H9RESGL", UserFilter{ExcludeDeleted: true, ExcludeBots: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := pretty.Compare(ids, []string{"U0G9QF9C6"}); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+
+	emails, err := GetChannelMemberEmails(client, "C1H9RESGL", UserFilter{ExcludeBots: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := pretty.Compare(emails, []string{"spengler@ghostbusters.example.com", "stantz@ghostbusters.example.com"}); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+}
+
+func TestEmailsToSlackIDsFiltered(t *testing.T) {
+	testServ := httptest.NewServer(newUsersMux([]byte(mockUsersListResp), nil))
+	defer testServ.Close()
+
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+	notGlinda := UserFilter{Predicate: func(user slack.User) bool { return user.Name != "glinda" }}
+	resolution, err := EmailsToSlackIDsInclusive(client, []string{"spengler@ghostbusters.example.com", "glenda@south.oz.coven"}, notGlinda)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantResolution := &EmailResolution{
+		Matched:  []EmailMatch{{Email: "spengler@ghostbusters.example.com", UserID: "U0G9QF9C6"}},
+		Filtered: []EmailMatch{{Email: "glenda@south.oz.coven", UserID: "W07QCRPA4"}},
+	}
+	if diff := pretty.Compare(resolution, wantResolution); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+}
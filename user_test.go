@@ -1,14 +1,53 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/kylelemons/godebug/pretty"
 	"github.com/slack-go/slack"
 )
 
+// newUsersMux serves users.list from respUsersList, and users.lookupByEmail
+// by searching the same list. Calls to each method are counted in calls if set.
+func newUsersMux(respUsersList []byte, calls map[string]int) *http.ServeMux {
+	var list struct {
+		OK      bool         `json:"ok"`
+		Members []slack.User `json:"members"`
+	}
+	_ = json.Unmarshal(respUsersList, &list)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		if calls != nil {
+			calls["users.list"]++
+		}
+		_, _ = w.Write(respUsersList)
+	})
+	mux.HandleFunc("/users.lookupByEmail", func(w http.ResponseWriter, r *http.Request) {
+		if calls != nil {
+			calls["users.lookupByEmail"]++
+		}
+		if !list.OK {
+			_, _ = w.Write(respUsersList)
+			return
+		}
+		for _, user := range list.Members {
+			if user.Profile.Email == r.FormValue("email") {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "user": user})
+				return
+			}
+		}
+		_, _ = w.Write([]byte(mockUsersNotFoundErrResp))
+	})
+
+	return mux
+}
+
 func TestEmailsToSlackIDs(t *testing.T) {
 	testCases := []struct {
 		description   string
@@ -24,19 +63,15 @@ func TestEmailsToSlackIDs(t *testing.T) {
 			wantIDs:       []string{"U0G9QF9C6", "W07QCRPA4"},
 		},
 		{
-			description:   "failure to retrieve users list",
+			description:   "failure to look up users",
 			respUsersList: []byte(mockUsersListErrResp),
+			emails:        []string{"spengler@ghostbusters.example.com"},
 			wantErr:       "invalid_cursor",
 		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
-			mux := http.NewServeMux()
-			mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
-				_, _ = w.Write(tc.respUsersList)
-			})
-
-			testServ := httptest.NewServer(mux)
+			testServ := httptest.NewServer(newUsersMux(tc.respUsersList, nil))
 			defer testServ.Close()
 
 			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
@@ -71,37 +106,57 @@ func TestEmailsToSlackIDs(t *testing.T) {
 
 func TestEmailsToSlackIDsInclusive(t *testing.T) {
 	testCases := []struct {
-		description   string
-		respUsersList []byte
-		wantErr       string
-		emails        []string
-		wantIDs       []string
+		description    string
+		respUsersList  []byte
+		wantErr        string
+		emails         []string
+		wantResolution *EmailResolution
 	}{
 		{
 			description:   "successful retrieval of member emails",
 			respUsersList: []byte(mockUsersListResp),
 			emails:        []string{"spengler@ghostbusters.example.com", "glenda@south.oz.coven"},
-			wantIDs:       []string{"U0G9QF9C6", "W07QCRPA4"},
+			wantResolution: &EmailResolution{
+				Matched: []EmailMatch{
+					{Email: "spengler@ghostbusters.example.com", UserID: "U0G9QF9C6"},
+					{Email: "glenda@south.oz.coven", UserID: "W07QCRPA4"},
+				},
+			},
+		},
+		{
+			description:   "emails normalized and deduplicated, unmatched emails reported",
+			respUsersList: []byte(mockUsersListResp),
+			emails:        []string{" Glenda@South.OZ.coven ", "venkman@ghostbusters.example.com", "glenda@south.oz.coven", ""},
+			wantResolution: &EmailResolution{
+				Matched:   []EmailMatch{{Email: "glenda@south.oz.coven", UserID: "W07QCRPA4"}},
+				Unmatched: []string{"venkman@ghostbusters.example.com"},
+			},
+		},
+		{
+			description:   "deactivated and bot users reported separately",
+			respUsersList: []byte(mockUsersListInactiveResp),
+			emails:        []string{"slimer@ghostbusters.example.com", "spengler@ghostbusters.example.com", "stantz@ghostbusters.example.com"},
+			wantResolution: &EmailResolution{
+				Matched:     []EmailMatch{{Email: "spengler@ghostbusters.example.com", UserID: "U0G9QF9C6"}},
+				Deactivated: []EmailMatch{{Email: "stantz@ghostbusters.example.com", UserID: "U0STANTZ1"}},
+				Bots:        []EmailMatch{{Email: "slimer@ghostbusters.example.com", UserID: "U0SLIMER1"}},
+			},
 		},
 		{
-			description:   "failure to retrieve users list",
+			description:   "failure to look up users",
 			respUsersList: []byte(mockUsersListErrResp),
+			emails:        []string{"spengler@ghostbusters.example.com"},
 			wantErr:       "invalid_cursor",
 		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
-			mux := http.NewServeMux()
-			mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
-				_, _ = w.Write(tc.respUsersList)
-			})
-
-			testServ := httptest.NewServer(mux)
+			testServ := httptest.NewServer(newUsersMux(tc.respUsersList, nil))
 			defer testServ.Close()
 
 			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
 
-			idEmailPairs, err := EmailsToSlackIDsInclusive(client, tc.emails)
+			resolution, err := EmailsToSlackIDsInclusive(client, tc.emails)
 
 			if tc.wantErr == "" && err != nil {
 				t.Fatalf("unexpected error: %v", err)
@@ -117,17 +172,177 @@ func TestEmailsToSlackIDsInclusive(t *testing.T) {
 				return
 			}
 
-			if len(idEmailPairs) != len(tc.wantIDs) {
-				t.Fatalf("expected to receive %v ids, got %v instead", len(tc.wantIDs), len(idEmailPairs))
+			if diff := pretty.Compare(resolution, tc.wantResolution); diff != "" {
+				t.Fatalf("-got +want %s\n", diff)
+			}
+		})
+	}
+}
+
+func TestEmailResolverEmptyInput(t *testing.T) {
+	calls := make(map[string]int)
+	testServ := httptest.NewServer(newUsersMux([]byte(mockUsersListResp), calls))
+	defer testServ.Close()
+
+	resolver := &EmailResolver{
+		Client:          slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL))),
+		LookupThreshold: -1,
+	}
+
+	resolution, err := resolver.EmailsToSlackIDsInclusive([]string{"", "  "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := pretty.Compare(resolution, &EmailResolution{}); diff != "" {
+		t.Fatalf("-got +want %s\n", diff)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected no API calls, got: %v", calls)
+	}
+}
+
+func TestEmailResolverStrategy(t *testing.T) {
+	emails := []string{"spengler@ghostbusters.example.com", "glenda@south.oz.coven", "venkman@ghostbusters.example.com"}
+
+	testCases := []struct {
+		description     string
+		lookupThreshold int
+		respUsersList   []byte
+		wantCalls       map[string]int
+		wantErr         string
+	}{
+		{
+			description: "small input looked up by email",
+			wantCalls:   map[string]int{"users.lookupByEmail": 3},
+		},
+		{
+			description:     "input above threshold resolved by listing users",
+			lookupThreshold: 2,
+			wantCalls:       map[string]int{"users.list": 1},
+		},
+		{
+			description:     "negative threshold always lists users",
+			lookupThreshold: -1,
+			wantCalls:       map[string]int{"users.list": 1},
+		},
+		{
+			description:   "failure to look up email",
+			respUsersList: []byte(mockUsersListErrResp),
+			wantCalls:     map[string]int{"users.lookupByEmail": 3},
+			wantErr:       "invalid_cursor",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			respUsersList := tc.respUsersList
+			if respUsersList == nil {
+				respUsersList = []byte(mockUsersListResp)
 			}
 
-			for i, idEmailPair := range idEmailPairs {
-				if tc.emails[i] != idEmailPair[0] {
-					t.Fatalf("expected email: %v, got: %v", tc.emails[1], idEmailPair[0])
+			calls := make(map[string]int)
+			testServ := httptest.NewServer(newUsersMux(respUsersList, calls))
+			defer testServ.Close()
+
+			resolver := &EmailResolver{
+				Client:          slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL))),
+				LookupThreshold: tc.lookupThreshold,
+				Concurrency:     1,
+			}
+
+			resolution, err := resolver.EmailsToSlackIDsInclusive(emails)
+
+			if diff := pretty.Compare(calls, tc.wantCalls); diff != "" {
+				t.Fatalf("-got +want %s\n", diff)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
 				}
-				if tc.wantIDs[i] != idEmailPair[1] {
-					t.Fatalf("expected id: %v, got: %v", tc.wantIDs[1], idEmailPair[1])
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
 				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			wantResolution := &EmailResolution{
+				Matched: []EmailMatch{
+					{Email: "spengler@ghostbusters.example.com", UserID: "U0G9QF9C6"},
+					{Email: "glenda@south.oz.coven", UserID: "W07QCRPA4"},
+				},
+				Unmatched: []string{"venkman@ghostbusters.example.com"},
+			}
+			if diff := pretty.Compare(resolution, wantResolution); diff != "" {
+				t.Fatalf("-got +want %s\n", diff)
+			}
+		})
+	}
+}
+
+func TestUserLocation(t *testing.T) {
+	testCases := []struct {
+		description  string
+		respUserInfo string
+		wantZone     string
+		wantOffset   int
+		wantErr      string
+	}{
+		{
+			description:  "timezone loaded from profile",
+			respUserInfo: `{"ok": true, "user": {"id": "U0G9QF9C6", "tz": "Asia/Tokyo", "tz_label": "Japan Standard Time", "tz_offset": 32400}}`,
+			wantZone:     "JST",
+			wantOffset:   32400,
+		},
+		{
+			description:  "unknown timezone falls back to offset",
+			respUserInfo: `{"ok": true, "user": {"id": "U0G9QF9C6", "tz": "Mars/Olympus_Mons", "tz_label": "Olympus Mons Time", "tz_offset": -3600}}`,
+			wantZone:     "Olympus Mons Time",
+			wantOffset:   -3600,
+		},
+		{
+			description:  "failure to retrieve user",
+			respUserInfo: `{"ok": false, "error": "user_not_found"}`,
+			wantErr:      "user_not_found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/users.info", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tc.respUserInfo))
+			})
+
+			testServ := httptest.NewServer(mux)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+			loc, err := UserLocation(client, "U0G9QF9C6")
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			zone, offset := time.Date(2020, time.April, 1, 0, 0, 0, 0, loc).Zone()
+			if zone != tc.wantZone || offset != tc.wantOffset {
+				t.Fatalf("expected zone %s (%d), got %s (%d)", tc.wantZone, tc.wantOffset, zone, offset)
 			}
 		})
 	}
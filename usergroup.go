@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+var (
+	ErrUserGroupNotFound = errors.New("user group not found")
+	// ErrEmptyUserGroup is returned when syncing would leave a user group with
+	// no members, which Slack does not allow (disable the group instead)
+	ErrEmptyUserGroup = errors.New("user group requires at least one member")
+)
+
+var (
+	userGroupIDPattern      = regexp.MustCompile(`^S[A-Z0-9]{6,}$`)
+	userGroupMentionPattern = regexp.MustCompile(`^<!subteam\^(S[A-Z0-9]+)(?:\|[^>]*)?>$`)
+)
+
+// ResolveUserGroup returns the ID of the user group referred to by ref, which
+// may be an ID (S123ABC), a mention as it appears in message text
+// (<!subteam^S123ABC|@oncall>) or a handle with or without the leading @.
+// Returns ErrUserGroupNotFound if no group has the handle.
+func ResolveUserGroup(client *slack.Client, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+
+	if m := userGroupMentionPattern.FindStringSubmatch(ref); m != nil {
+		return m[1], nil
+	}
+
+	if userGroupIDPattern.MatchString(ref) {
+		return ref, nil
+	}
+
+	group, err := findUserGroupByHandle(client, ref)
+	if err != nil {
+		return "", err
+	}
+
+	return group.ID, nil
+}
+
+// GetUserGroupMembers returns the IDs of the members of the user group
+// referred to by ref, see ResolveUserGroup
+func GetUserGroupMembers(client *slack.Client, ref string) ([]string, error) {
+	groupID, err := ResolveUserGroup(client, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.GetUserGroupMembers(groupID)
+}
+
+// UpsertUserGroup creates a user group with the handle, name, description and
+// default channels of the one provided, or updates the existing group with the
+// same handle to match, re-enabling it if it was disabled
+func UpsertUserGroup(client *slack.Client, group slack.UserGroup) (*slack.UserGroup, error) {
+	existing, err := findUserGroupByHandle(client, group.Handle)
+	if err == ErrUserGroupNotFound {
+		created, err := client.CreateUserGroup(group)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create user group")
+		}
+		return &created, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.DateDelete != 0 {
+		if _, err := client.EnableUserGroup(existing.ID); err != nil {
+			return nil, errors.Wrapf(err, "failed to enable user group")
+		}
+	}
+
+	opts := []slack.UpdateUserGroupsOption{
+		slack.UpdateUserGroupsOptionName(group.Name),
+		slack.UpdateUserGroupsOptionDescription(&group.Description),
+	}
+	if len(group.Prefs.Channels) > 0 {
+		opts = append(opts, slack.UpdateUserGroupsOptionChannels(group.Prefs.Channels))
+	}
+
+	updated, err := client.UpdateUserGroup(existing.ID, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update user group")
+	}
+
+	return &updated, nil
+}
+
+// SyncUserGroupMembers replaces the members of the user group referred to by
+// ref with the users the emails resolve to (see EmailsToSlackIDsInclusive),
+// returning the resolution so that unmatched emails can be reported
+func SyncUserGroupMembers(client *slack.Client, ref string, emails []string, filters ...UserFilter) (*EmailResolution, error) {
+	groupID, err := ResolveUserGroup(client, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	resolution, err := EmailsToSlackIDsInclusive(client, emails, filters...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve emails")
+	}
+
+	ids := resolution.IDs()
+	if len(ids) == 0 {
+		return resolution, ErrEmptyUserGroup
+	}
+
+	if _, err := client.UpdateUserGroupMembers(groupID, strings.Join(ids, ",")); err != nil {
+		return resolution, errors.Wrapf(err, "failed to update user group members")
+	}
+
+	return resolution, nil
+}
+
+// InviteUserGroup invites every member of the user group referred to by ref
+// to the channel, see ResolveUserGroup and InviteUsers
+func (c *Channel) InviteUserGroup(ref string) (*BulkResult, error) {
+	if c.UserClient == nil {
+		return nil, errors.New("method requires user client")
+	}
+
+	memberIDs, err := GetUserGroupMembers(c.UserClient, ref)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get user group members")
+	}
+
+	return c.inviteUsers(c.ChannelID, memberIDs)
+}
+
+// findUserGroupByHandle looks through every user group, disabled ones
+// included, for the one with the handle
+func findUserGroupByHandle(client *slack.Client, handle string) (*slack.UserGroup, error) {
+	handle = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+
+	groups, err := client.GetUserGroups(slack.GetUserGroupsOptionIncludeDisabled(true))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list user groups")
+	}
+
+	for _, group := range groups {
+		if strings.ToLower(group.Handle) == handle {
+			return &group, nil
+		}
+	}
+
+	return nil, ErrUserGroupNotFound
+}
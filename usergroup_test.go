@@ -0,0 +1,252 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/slack-go/slack"
+)
+
+func writeUserGroup(w http.ResponseWriter, r *http.Request) {
+	id := r.FormValue("usergroup")
+	if id == "" {
+		id = "S0NEWGRP1"
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok": true,
+		"usergroup": map[string]interface{}{
+			"id":          id,
+			"name":        r.FormValue("name"),
+			"handle":      r.FormValue("handle"),
+			"description": r.FormValue("description"),
+		},
+	})
+}
+
+func TestResolveUserGroup(t *testing.T) {
+	testCases := []struct {
+		description string
+		ref         string
+		respList    []byte
+		wantID      string
+		wantErr     string
+	}{
+		{description: "mention with handle", ref: "<!subteam^S0614TZR7|@oncall>", wantID: "S0614TZR7"},
+		{description: "mention without handle", ref: "<!subteam^S0614TZR7>", wantID: "S0614TZR7"},
+		{description: "id", ref: "S0614TZR7", wantID: "S0614TZR7"},
+		{description: "handle with @", ref: "@OnCall", respList: []byte(mockUserGroupsListResp), wantID: "S0614TZR7"},
+		{description: "handle without @", ref: "design", respList: []byte(mockUserGroupsListResp), wantID: "S0615G0KT"},
+		{description: "unknown handle", ref: "@ghostbusters", respList: []byte(mockUserGroupsListResp), wantErr: ErrUserGroupNotFound.Error()},
+		{description: "failure to list user groups", ref: "@oncall", respList: []byte(mockUserGroupErrResp), wantErr: "failed to list user groups: no_such_subteam"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var listed bool
+			mux := http.NewServeMux()
+			mux.HandleFunc("/usergroups.list", func(w http.ResponseWriter, r *http.Request) {
+				listed = true
+				_, _ = w.Write(tc.respList)
+			})
+
+			testServ := httptest.NewServer(mux)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+			id, err := ResolveUserGroup(client, tc.ref)
+
+			if tc.respList == nil && listed {
+				t.Fatal("expected user groups not to be listed")
+			}
+
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+			}
+
+			if id != tc.wantID {
+				t.Fatalf("expected user group id: %s, got: %s", tc.wantID, id)
+			}
+		})
+	}
+}
+
+func TestUpsertUserGroup(t *testing.T) {
+	testCases := []struct {
+		description string
+		group       slack.UserGroup
+		wantCalls   []string
+		wantID      string
+	}{
+		{
+			description: "new group created",
+			group:       slack.UserGroup{Name: "Ghostbusters", Handle: "ghostbusters"},
+			wantCalls:   []string{"usergroups.list", "usergroups.create"},
+			wantID:      "S0NEWGRP1",
+		},
+		{
+			description: "existing group updated",
+			group:       slack.UserGroup{Name: "On-call rotation", Handle: "oncall"},
+			wantCalls:   []string{"usergroups.list", "usergroups.update"},
+			wantID:      "S0614TZR7",
+		},
+		{
+			description: "disabled group enabled and updated",
+			group:       slack.UserGroup{Name: "Design", Handle: "design"},
+			wantCalls:   []string{"usergroups.list", "usergroups.enable", "usergroups.update"},
+			wantID:      "S0615G0KT",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var calls []string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/usergroups.list", func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, "usergroups.list")
+				_, _ = w.Write([]byte(mockUserGroupsListResp))
+			})
+			for _, method := range []string{"usergroups.create", "usergroups.update", "usergroups.enable"} {
+				method := method
+				mux.HandleFunc("/"+method, func(w http.ResponseWriter, r *http.Request) {
+					calls = append(calls, method)
+					writeUserGroup(w, r)
+				})
+			}
+
+			testServ := httptest.NewServer(mux)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+			group, err := UpsertUserGroup(client, tc.group)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := pretty.Compare(calls, tc.wantCalls); diff != "" {
+				t.Fatalf("-got +want %s\n", diff)
+			}
+
+			if group.ID != tc.wantID {
+				t.Fatalf("expected user group id: %s, got: %s", tc.wantID, group.ID)
+			}
+		})
+	}
+}
+
+func TestSyncUserGroupMembers(t *testing.T) {
+	testCases := []struct {
+		description string
+		emails      []string
+		respUpdate  []byte
+		wantUsers   string
+		wantErr     string
+	}{
+		{
+			description: "members replaced with resolved users",
+			emails:      []string{"spengler@ghostbusters.example.com", "venkman@ghostbusters.example.com", "glenda@south.oz.coven"},
+			wantUsers:   "U0G9QF9C6,W07QCRPA4",
+		},
+		{
+			description: "no resolved users",
+			emails:      []string{"venkman@ghostbusters.example.com"},
+			wantErr:     ErrEmptyUserGroup.Error(),
+		},
+		{
+			description: "failure to update members",
+			emails:      []string{"spengler@ghostbusters.example.com"},
+			respUpdate:  []byte(mockUserGroupErrResp),
+			wantUsers:   "U0G9QF9C6",
+			wantErr:     "failed to update user group members: no_such_subteam",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var gotUsers string
+			mux := newUsersMux([]byte(mockUsersListResp), nil)
+			mux.HandleFunc("/usergroups.users.update", func(w http.ResponseWriter, r *http.Request) {
+				gotUsers = r.FormValue("users")
+				if tc.respUpdate != nil {
+					_, _ = w.Write(tc.respUpdate)
+					return
+				}
+				writeUserGroup(w, r)
+			})
+
+			testServ := httptest.NewServer(mux)
+			defer testServ.Close()
+
+			client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+
+			resolution, err := SyncUserGroupMembers(client, "<!subteam^S0614TZR7|@oncall>", tc.emails)
+
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error but did not receive one")
+				}
+				if err.Error() != tc.wantErr {
+					t.Fatalf("expected to receive error: %s, got: %s", tc.wantErr, err)
+				}
+			}
+
+			if gotUsers != tc.wantUsers {
+				t.Fatalf("expected users: %s, got: %s", tc.wantUsers, gotUsers)
+			}
+
+			if resolution == nil {
+				t.Fatal("expected resolution to be returned")
+			}
+		})
+	}
+}
+
+func TestInviteUserGroup(t *testing.T) {
+	var gotUsers string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/usergroups.users.list", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(mockUserGroupMembersResp))
+	})
+	mux.HandleFunc("/conversations.invite", func(w http.ResponseWriter, r *http.Request) {
+		gotUsers = r.FormValue("users")
+		_, _ = w.Write([]byte(mockInviteMembersResp))
+	})
+
+	testServ := httptest.NewServer(mux)
+	defer testServ.Close()
+
+	client := slack.New("x012345", slack.OptionAPIURL(fmt.Sprintf("%v/", testServ.URL)))
+	channel := Channel{
+		UserClient: client,
+		ChannelID:  "C0DEL09A5",
+	}
+
+	result, err := channel.InviteUserGroup("S0614TZR7")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotUsers != "U0G9QF9C6,W07QCRPA4" {
+		t.Fatalf("expected users: U0G9QF9C6,W07QCRPA4, got: %s", gotUsers)
+	}
+
+	checkBulkResult(t, result, wantBulkResult{succeeded: []string{"U0G9QF9C6", "W07QCRPA4"}})
+}
@@ -76,7 +76,7 @@ func TestVerifySlashCommand(t *testing.T) {
 			},
 			secret:              testSecret2,
 			ts:                  testReqTsValid,
-			containsRespPattern: "Expected signing signature:",
+			containsRespPattern: "Computed unexpected signature of:",
 		},
 		{
 			description:   "using middleware and invalid signing signature, verify fails and req killed, no/empty fail method provided so no extra action",
@@ -225,7 +225,7 @@ func TestVerifyInteractionCallback(t *testing.T) {
 			},
 			secret:              testSecret2,
 			ts:                  testReqTsValid,
-			containsRespPattern: "Expected signing signature:",
+			containsRespPattern: "Computed unexpected signature of:",
 		},
 		{
 			description:   "using middleware and invalid signing signature, verify fails and req killed, no/empty fail method provided so no extra action",
@@ -289,7 +289,10 @@ func TestVerifyInteractionCallback(t *testing.T) {
 		},
 		APIAppID: "A00000000",
 		Container: slack.Container{
-			Type: "message",
+			Type:        "message",
+			MessageTs:   "1589970639.001400",
+			ChannelID:   "G0000000",
+			IsEphemeral: true,
 		},
 	}
 